@@ -0,0 +1,251 @@
+package tuna
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PaymentMethodCreditCard is the PaymentMethodType of card payments.
+const PaymentMethodCreditCard = "1"
+
+// ErrInvalidPayment is matched by the ValidationErrors returned by
+// PaymentBuilder.Build.
+var ErrInvalidPayment = errors.New("tuna: invalid payment")
+
+// FieldError is a problem with one field of an InitRequest. Field is the path
+// of the field using the Go field names of InitRequest, e.g.
+// "PaymentItems[0].Amount" or "Customer.Email".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors lists every problem found in an InitRequest.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidPayment, strings.Join(msgs, "; "))
+}
+
+func (e ValidationErrors) Unwrap() error {
+	return ErrInvalidPayment
+}
+
+// PaymentBuilder builds an InitRequest step by step, e.g.
+//
+//	request, err := tuna.NewPayment("order-1").
+//		Customer(tuna.Customer{ID: "42", Email: "john@example.com"}).
+//		AddItem(tuna.PaymentItem{ProductDescription: "Book", Amount: 50, ItemQuantity: 2}).
+//		PayWithToken(token, 100).
+//		Installments(3).
+//		Build()
+//
+// Methods that configure a payment method, such as Installments, apply to the
+// last one added.
+type PaymentBuilder struct {
+	request InitRequest
+	errs    ValidationErrors
+}
+
+// NewPayment starts an InitRequest for partnerUniqueID.
+func NewPayment(partnerUniqueID string) *PaymentBuilder {
+	return &PaymentBuilder{request: InitRequest{PartnerUniqueID: partnerUniqueID}}
+}
+
+// Customer sets the customer paying; both its ID and Email are required.
+func (b *PaymentBuilder) Customer(customer Customer) *PaymentBuilder {
+	b.request.Customer = customer
+	return b
+}
+
+// AddItem adds an item. Its Amount is the unit amount; ItemQuantity defaults
+// to 1.
+func (b *PaymentBuilder) AddItem(item PaymentItem) *PaymentBuilder {
+	if item.ItemQuantity == 0 {
+		item.ItemQuantity = 1
+	}
+	b.request.PaymentItems = append(b.request.PaymentItems, item)
+	return b
+}
+
+// PayWithToken adds a card payment of amount using a saved card token.
+func (b *PaymentBuilder) PayWithToken(token string, amount int) *PaymentBuilder {
+	return b.PayWith(PaymentMethods{
+		PaymentMethodType: PaymentMethodCreditCard,
+		Amount:            amount,
+		CardInfo:          CardInfo{Token: token},
+	})
+}
+
+// PayWithCard adds a card payment of amount using card.
+func (b *PaymentBuilder) PayWithCard(card CardInfo, amount int) *PaymentBuilder {
+	return b.PayWith(PaymentMethods{
+		PaymentMethodType: PaymentMethodCreditCard,
+		Amount:            amount,
+		CardInfo:          card,
+	})
+}
+
+// PayWith adds any payment method. Installments defaults to 1.
+func (b *PaymentBuilder) PayWith(method PaymentMethods) *PaymentBuilder {
+	if method.Installments == 0 {
+		method.Installments = 1
+	}
+	b.request.PaymentData.PaymentMethods = append(b.request.PaymentData.PaymentMethods, method)
+	return b
+}
+
+// Installments sets the number of installments of the last payment method.
+func (b *PaymentBuilder) Installments(n int) *PaymentBuilder {
+	if m := b.lastMethod("Installments"); m != nil {
+		m.Installments = n
+	}
+	return b
+}
+
+// Billing sets the billing info of the card of the last payment method.
+func (b *PaymentBuilder) Billing(billing BillingInfo) *PaymentBuilder {
+	if m := b.lastMethod("CardInfo.BillingInfo"); m != nil {
+		m.CardInfo.BillingInfo = billing
+	}
+	return b
+}
+
+// SaveCard asks Tuna to save the card of the last payment method.
+func (b *PaymentBuilder) SaveCard() *PaymentBuilder {
+	if m := b.lastMethod("CardInfo.SaveCard"); m != nil {
+		m.CardInfo.SaveCard = true
+	}
+	return b
+}
+
+// Country sets the country code of the payment, e.g. "BR".
+func (b *PaymentBuilder) Country(code string) *PaymentBuilder {
+	b.request.PaymentData.CountryCode = code
+	return b
+}
+
+// DeliveryAddress sets where the order is delivered.
+func (b *PaymentBuilder) DeliveryAddress(address DeliveryAddress) *PaymentBuilder {
+	b.request.PaymentData.DeliveryAddress = address
+	return b
+}
+
+// AntiFraud sets the anti-fraud data of the payment. Items carry their own.
+func (b *PaymentBuilder) AntiFraud(antiFraud AntiFraud) *PaymentBuilder {
+	b.request.PaymentData.AntiFraud = antiFraud
+	return b
+}
+
+// FrontData sets the data collected by the checkout page, such as its
+// session ID.
+func (b *PaymentBuilder) FrontData(front FrontData) *PaymentBuilder {
+	b.request.FrontData = front
+	return b
+}
+
+func (b *PaymentBuilder) lastMethod(field string) *PaymentMethods {
+	methods := b.request.PaymentData.PaymentMethods
+	if len(methods) == 0 {
+		b.errs = append(b.errs, FieldError{
+			Field:   "PaymentData.PaymentMethods",
+			Message: field + " set before any payment method was added",
+		})
+		return nil
+	}
+	return &methods[len(methods)-1]
+}
+
+// Build returns a copy of the InitRequest, or ValidationErrors listing every
+// missing or inconsistent field. The builder can be reused afterwards without
+// changing the returned request.
+func (b *PaymentBuilder) Build() (InitRequest, error) {
+	errs := append(ValidationErrors(nil), b.errs...)
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	r := b.request
+	if r.PartnerUniqueID == "" {
+		add("PartnerUniqueID", "is required")
+	}
+	if r.Customer.ID == "" {
+		add("Customer.ID", "is required")
+	}
+	if r.Customer.Email == "" {
+		add("Customer.Email", "is required")
+	} else if !strings.Contains(r.Customer.Email, "@") {
+		add("Customer.Email", "is not an email address")
+	}
+
+	if len(r.PaymentItems) == 0 {
+		add("PaymentItems", "at least one item is required")
+	}
+	var itemsTotal int
+	for i, item := range r.PaymentItems {
+		field := fmt.Sprintf("PaymentItems[%d]", i)
+		if item.ProductDescription == "" {
+			add(field+".ProductDescription", "is required")
+		}
+		if item.Amount <= 0 {
+			add(field+".Amount", "must be positive")
+		}
+		if item.ItemQuantity <= 0 {
+			add(field+".ItemQuantity", "must be positive")
+		}
+		itemsTotal += item.Amount * item.ItemQuantity
+	}
+
+	methods := r.PaymentData.PaymentMethods
+	if len(methods) == 0 {
+		add("PaymentData.PaymentMethods", "at least one payment method is required")
+	}
+	var methodsTotal int
+	for i, m := range methods {
+		field := fmt.Sprintf("PaymentData.PaymentMethods[%d]", i)
+		if m.PaymentMethodType == "" {
+			add(field+".PaymentMethodType", "is required")
+		}
+		if m.Amount <= 0 {
+			add(field+".Amount", "must be positive")
+		}
+		if m.Installments <= 0 {
+			add(field+".Installments", "must be positive")
+		}
+		if m.PaymentMethodType == PaymentMethodCreditCard && isBlank(m.CardInfo.Token) && isBlank(m.CardInfo.CardNumber) {
+			add(field+".CardInfo", "a token or a card number is required")
+		}
+		methodsTotal += m.Amount
+	}
+
+	if len(r.PaymentItems) > 0 && len(methods) > 0 && itemsTotal != methodsTotal {
+		add("PaymentData.PaymentMethods", "amounts sum to %d but items sum to %d", methodsTotal, itemsTotal)
+	}
+
+	if len(errs) > 0 {
+		return InitRequest{}, errs
+	}
+
+	r.PaymentItems = append([]PaymentItem(nil), r.PaymentItems...)
+	r.PaymentData.PaymentMethods = append([]PaymentMethods(nil), methods...)
+	return r, nil
+}
+
+// isBlank reports whether v is nil, a zero value such as 0 or "", or a string
+// of spaces.
+func isBlank(v interface{}) bool {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
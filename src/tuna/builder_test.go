@@ -0,0 +1,107 @@
+package tuna
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentBuilder(t *testing.T) {
+	t.Run("should build an init request", func(t *testing.T) {
+		request, err := NewPayment("order-1").
+			Customer(Customer{ID: "42", Email: "john@example.com"}).
+			AddItem(PaymentItem{ProductDescription: "Book", Amount: 30, ItemQuantity: 2}).
+			AddItem(PaymentItem{ProductDescription: "Pen", Amount: 40}).
+			PayWithToken("card-token", 100).
+			Installments(3).
+			Billing(BillingInfo{Document: "123"}).
+			Country("BR").
+			FrontData(FrontData{SessionID: "session"}).
+			Build()
+		require.NoError(t, err)
+
+		assert.Equal(t, "order-1", request.PartnerUniqueID)
+		assert.Equal(t, 1, request.PaymentItems[1].ItemQuantity)
+		require.Len(t, request.PaymentData.PaymentMethods, 1)
+		method := request.PaymentData.PaymentMethods[0]
+		assert.Equal(t, PaymentMethodCreditCard, method.PaymentMethodType)
+		assert.Equal(t, 3, method.Installments)
+		assert.Equal(t, "card-token", method.CardInfo.Token)
+		assert.Equal(t, "123", method.CardInfo.BillingInfo.Document)
+		assert.Equal(t, "BR", request.PaymentData.CountryCode)
+		assert.Equal(t, "session", request.FrontData.SessionID)
+	})
+
+	t.Run("should list every field error", func(t *testing.T) {
+		_, err := NewPayment("").
+			Installments(2).
+			Customer(Customer{ID: "42", Email: "john"}).
+			AddItem(PaymentItem{Amount: 50}).
+			PayWithToken("", 60).
+			Build()
+		assert.ErrorIs(t, err, ErrInvalidPayment)
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		var fields []string
+		for _, fe := range errs {
+			fields = append(fields, fe.Field)
+		}
+		assert.Equal(t, []string{
+			"PaymentData.PaymentMethods",
+			"PartnerUniqueID",
+			"Customer.Email",
+			"PaymentItems[0].ProductDescription",
+			"PaymentData.PaymentMethods[0].CardInfo",
+			"PaymentData.PaymentMethods",
+		}, fields)
+		assert.Equal(t, "Installments set before any payment method was added", errs[0].Message)
+		assert.Contains(t, err.Error(), "amounts sum to 60 but items sum to 50")
+	})
+
+	t.Run("should treat empty card numbers as missing", func(t *testing.T) {
+		for _, number := range []interface{}{nil, "", "  ", 0, int64(0)} {
+			_, err := NewPayment("order-1").
+				Customer(Customer{ID: "42", Email: "john@example.com"}).
+				AddItem(PaymentItem{ProductDescription: "Book", Amount: 100}).
+				PayWithCard(CardInfo{CardNumber: number}, 100).
+				Build()
+
+			var errs ValidationErrors
+			require.ErrorAs(t, err, &errs, "%#v", number)
+			assert.Equal(t, "PaymentData.PaymentMethods[0].CardInfo", errs[0].Field)
+		}
+
+		_, err := NewPayment("order-1").
+			Customer(Customer{ID: "42", Email: "john@example.com"}).
+			AddItem(PaymentItem{ProductDescription: "Book", Amount: 100}).
+			PayWithCard(CardInfo{CardNumber: "4111111111111111"}, 100).
+			Build()
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not share slices with the builder", func(t *testing.T) {
+		b := NewPayment("order-1").
+			Customer(Customer{ID: "42", Email: "john@example.com"}).
+			AddItem(PaymentItem{ProductDescription: "Book", Amount: 100}).
+			PayWithToken("card-token", 100)
+		request, err := b.Build()
+		require.NoError(t, err)
+
+		b.Installments(6).AddItem(PaymentItem{ProductDescription: "Pen", Amount: 40})
+		b.request.PaymentItems[0].Amount = 1
+
+		assert.Equal(t, 1, request.PaymentData.PaymentMethods[0].Installments)
+		assert.Equal(t, 100, request.PaymentItems[0].Amount)
+		assert.Len(t, request.PaymentItems, 1)
+	})
+
+	t.Run("should require items and payment methods", func(t *testing.T) {
+		_, err := NewPayment("order-1").Customer(Customer{ID: "42", Email: "john@example.com"}).Build()
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		assert.Len(t, errs, 2)
+	})
+}
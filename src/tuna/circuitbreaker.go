@@ -0,0 +1,213 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureRatio   = 0.5
+	defaultMinRequests    = 10
+	defaultCircuitWindow  = time.Minute
+	defaultOpenTimeout    = 30 * time.Second
+	defaultHalfOpenProbes = 1
+)
+
+// ErrCircuitOpen is returned without calling Tuna while the circuit breaker is
+// open.
+var ErrCircuitOpen = errors.New("tuna: circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breaker. Connection errors, 5xx
+// and 429 responses count as failures. Zero fields fall back to their defaults.
+type CircuitBreakerConfig struct {
+	// FailureRatio of calls within Window that trips the breaker. Defaults to 0.5.
+	FailureRatio float64
+	// MinRequests is the number of calls within Window needed before the
+	// breaker may trip. Defaults to 10.
+	MinRequests int
+	// Window is the period over which failures are counted. Defaults to 1 minute.
+	Window time.Duration
+	// OpenTimeout is how long the breaker stays open before letting probe
+	// requests through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of successful probe requests needed to
+	// close the breaker again. Defaults to 1.
+	HalfOpenProbes int
+}
+
+type circuitBreaker struct {
+	conf CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+func newCircuitBreaker(conf *CircuitBreakerConfig) *circuitBreaker {
+	if conf == nil {
+		return nil
+	}
+
+	c := *conf
+	if c.FailureRatio <= 0 {
+		c.FailureRatio = defaultFailureRatio
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = defaultMinRequests
+	}
+	if c.Window <= 0 {
+		c.Window = defaultCircuitWindow
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = defaultOpenTimeout
+	}
+	if c.HalfOpenProbes <= 0 {
+		c.HalfOpenProbes = defaultHalfOpenProbes
+	}
+
+	return &circuitBreaker{conf: c, windowStart: time.Now()}
+}
+
+func (cb *circuitBreaker) State() CircuitState {
+	if cb == nil {
+		return CircuitClosed
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.advance(time.Now())
+	return cb.state
+}
+
+// allow returns ErrCircuitOpen if a call may not be sent right now.
+func (cb *circuitBreaker) allow() error {
+	if cb == nil {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.advance(time.Now())
+
+	switch cb.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.probes >= cb.conf.HalfOpenProbes {
+			return ErrCircuitOpen
+		}
+		cb.probes++
+	}
+
+	return nil
+}
+
+// record reports the outcome of a call let through by allow. ctx is the
+// context of the call.
+func (cb *circuitBreaker) record(ctx context.Context, resp *http.Response, err error) {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	// A call canceled by the caller, or cut short by the caller's own
+	// deadline, says nothing about Tuna's health; only give its probe slot
+	// back. Timeouts of the HTTP client itself still count as failures.
+	if err != nil && (errors.Is(err, context.Canceled) || ctx.Err() != nil) {
+		if cb.state == CircuitHalfOpen && cb.probes > 0 {
+			cb.probes--
+		}
+		return
+	}
+
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusTooManyRequests
+
+	now := time.Now()
+	cb.advance(now)
+
+	switch cb.state {
+	case CircuitHalfOpen:
+		if failed {
+			cb.open(now)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.conf.HalfOpenProbes {
+			cb.close(now)
+		}
+	case CircuitClosed:
+		cb.requests++
+		if failed {
+			cb.failures++
+		}
+		if cb.requests >= cb.conf.MinRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.conf.FailureRatio {
+			cb.open(now)
+		}
+	}
+}
+
+// advance moves the breaker along with time: it starts a new counting window
+// when closed and lets probes through once the open timeout has elapsed.
+func (cb *circuitBreaker) advance(now time.Time) {
+	switch cb.state {
+	case CircuitClosed:
+		if now.Sub(cb.windowStart) >= cb.conf.Window {
+			cb.windowStart = now
+			cb.requests = 0
+			cb.failures = 0
+		}
+	case CircuitOpen:
+		if now.Sub(cb.openedAt) >= cb.conf.OpenTimeout {
+			cb.state = CircuitHalfOpen
+			cb.probes = 0
+			cb.successes = 0
+		}
+	}
+}
+
+func (cb *circuitBreaker) open(now time.Time) {
+	cb.state = CircuitOpen
+	cb.openedAt = now
+}
+
+func (cb *circuitBreaker) close(now time.Time) {
+	cb.state = CircuitClosed
+	cb.windowStart = now
+	cb.requests = 0
+	cb.failures = 0
+}
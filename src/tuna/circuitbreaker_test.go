@@ -0,0 +1,98 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("should fail fast once tripped and close after a successful probe", func(t *testing.T) {
+		var calls int
+		code := http.StatusServiceUnavailable
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			calls++
+			return &http.Response{
+				StatusCode: code,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{
+			CircuitBreaker: &CircuitBreakerConfig{
+				FailureRatio: 0.5,
+				MinRequests:  2,
+				OpenTimeout:  10 * time.Millisecond,
+			},
+		})
+
+		for i := 0; i < 2; i++ {
+			_, err := api.Status(StatusRequest{})
+			var apiErr *APIError
+			assert.ErrorAs(t, err, &apiErr)
+		}
+		assert.Equal(t, CircuitOpen, api.CircuitState())
+
+		_, err := api.Status(StatusRequest{})
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, 2, calls)
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, CircuitHalfOpen, api.CircuitState())
+
+		code = http.StatusOK
+		_, err = api.Status(StatusRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, CircuitClosed, api.CircuitState())
+	})
+
+	t.Run("should reopen when a probe fails", func(t *testing.T) {
+		cb := newCircuitBreaker(&CircuitBreakerConfig{MinRequests: 1, OpenTimeout: time.Millisecond})
+		failure := &http.Response{StatusCode: http.StatusBadGateway}
+
+		assert.NoError(t, cb.allow())
+		cb.record(context.Background(), failure, nil)
+		assert.Equal(t, CircuitOpen, cb.State())
+
+		time.Sleep(time.Millisecond)
+		assert.NoError(t, cb.allow())
+		assert.ErrorIs(t, cb.allow(), ErrCircuitOpen)
+
+		cb.record(context.Background(), failure, nil)
+		assert.Equal(t, CircuitOpen, cb.State())
+	})
+
+	t.Run("should not count client errors as failures", func(t *testing.T) {
+		cb := newCircuitBreaker(&CircuitBreakerConfig{MinRequests: 1})
+
+		cb.record(context.Background(), &http.Response{StatusCode: http.StatusBadRequest}, nil)
+		assert.Equal(t, CircuitClosed, cb.State())
+	})
+
+	t.Run("should count only timeouts the caller did not cause", func(t *testing.T) {
+		cb := newCircuitBreaker(&CircuitBreakerConfig{MinRequests: 1, OpenTimeout: time.Minute})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		assert.NoError(t, cb.allow())
+		cb.record(ctx, nil, context.DeadlineExceeded)
+		assert.Equal(t, CircuitClosed, cb.State())
+
+		assert.NoError(t, cb.allow())
+		cb.record(context.Background(), nil, &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded})
+		assert.Equal(t, CircuitOpen, cb.State())
+	})
+
+	t.Run("should report closed without a circuit breaker", func(t *testing.T) {
+		api := newTestTokenClient(t, nil, Config{})
+		assert.Equal(t, CircuitClosed, api.CircuitState())
+	})
+}
@@ -0,0 +1,43 @@
+package tuna
+
+import "net/http"
+
+// Client gives access to both Tuna APIs from a single Config. Its token and
+// payment services share the HTTP client, middlewares and client-wide rate
+// limit; each keeps its own circuit breaker since they talk to different hosts.
+type Client struct {
+	token   *TokenClient
+	payment *PaymentClient
+}
+
+// NewClient returns a Client for the hosts of conf. It fails if conf has no
+// app token or no valid base URL for either API.
+func NewClient(httpClient *http.Client, conf Config) (*Client, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	tokenURL, _ := conf.tokenBaseURL()
+	paymentURL, _ := conf.paymentBaseURL()
+
+	tokenTransport := newTransport(httpClient, conf, tokenURL)
+	paymentTransport := tokenTransport.withBaseURL(paymentURL, newCircuitBreaker(conf.CircuitBreaker))
+
+	return &Client{
+		token:   &TokenClient{transport: tokenTransport},
+		payment: &PaymentClient{transport: paymentTransport},
+	}, nil
+}
+
+func (c *Client) Token() *TokenClient {
+	return c.token
+}
+
+func (c *Client) Payment() *PaymentClient {
+	return c.payment
+}
+
+// Service returns a PaymentAdapter backed by the services of c.
+func (c *Client) Service() *PaymentAdapter {
+	return NewTunaService(c.token, c.payment)
+}
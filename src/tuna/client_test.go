@@ -0,0 +1,61 @@
+package tuna
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	t.Run("should send each API to its own host", func(t *testing.T) {
+		var hosts []string
+		httpClient := NewTestClient(func(req *http.Request) *http.Response {
+			hosts = append(hosts, req.URL.Host)
+			assert.Equal(t, "app-token", req.Header.Get("x-tuna-apptoken"))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"sessionId": "session"}`)),
+				Header:     make(http.Header),
+			}
+		})
+
+		client, err := NewClient(httpClient, Config{
+			Environment:    "sandbox",
+			PaymentBaseURL: "https://payment.test",
+			AppToken:       "app-token",
+		})
+		require.NoError(t, err)
+
+		var tokenAPI TokenAPI = client.Token()
+		var paymentAPI PaymentAPI = client.Payment()
+		assert.NotNil(t, tokenAPI)
+		assert.NotNil(t, paymentAPI)
+
+		sessionID, err := client.Service().NewSession("1", "john@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "session", sessionID)
+
+		_, err = client.Payment().Options(OptionsRequest{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"token.tuna-demo.uy", "payment.test"}, hosts)
+	})
+
+	t.Run("should share the client-wide rate limit", func(t *testing.T) {
+		client, err := NewClient(nil, testConfig(Config{RateLimit: &RateLimit{MaxInFlight: 1}}))
+		require.NoError(t, err)
+
+		assert.Same(t, client.Token().transport.limiters, client.Payment().transport.limiters)
+		assert.NotSame(t, client.Token().transport, client.Payment().transport)
+	})
+
+	t.Run("should fail without a host for each API", func(t *testing.T) {
+		client, err := NewClient(nil, Config{TokenBaseURL: "https://token.test", AppToken: "app-token"})
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Nil(t, client)
+	})
+}
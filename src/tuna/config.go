@@ -0,0 +1,267 @@
+package tuna
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	EnvEnvironment    = "TUNA_ENVIRONMENT"
+	EnvBaseURL        = "TUNA_BASE_URL"
+	EnvTokenBaseURL   = "TUNA_TOKEN_BASE_URL"
+	EnvPaymentBaseURL = "TUNA_PAYMENT_BASE_URL"
+	EnvUserAgent      = "TUNA_USER_AGENT"
+	EnvAppToken       = "TUNA_APP_TOKEN"
+	EnvStrict         = "TUNA_STRICT"
+)
+
+var ErrInvalidConfig = errors.New("tuna: invalid config")
+
+// Environment holds the hosts of a Tuna environment.
+type Environment struct {
+	TokenBaseURL   string `json:"tokenBaseURL" yaml:"tokenBaseURL"`
+	PaymentBaseURL string `json:"paymentBaseURL" yaml:"paymentBaseURL"`
+}
+
+var (
+	Sandbox = Environment{
+		TokenBaseURL:   "https://token.tuna-demo.uy",
+		PaymentBaseURL: "https://sandbox.tuna-demo.uy",
+	}
+	Production = Environment{
+		TokenBaseURL:   "https://token.tunagateway.com",
+		PaymentBaseURL: "https://engine.tunagateway.com",
+	}
+)
+
+var environments = map[string]Environment{
+	"sandbox":    Sandbox,
+	"production": Production,
+}
+
+// Config configures a TokenClient or PaymentClient.
+type Config struct {
+	// Environment names the Tuna environment whose hosts are used when
+	// BaseURL is empty, e.g. "sandbox" or "production".
+	Environment string
+	// Environments adds or overrides named environments.
+	Environments map[string]Environment
+	// BaseURL overrides the hosts of the environment for both APIs.
+	BaseURL string
+	// TokenBaseURL and PaymentBaseURL override the host of a single API,
+	// taking precedence over BaseURL.
+	TokenBaseURL   string
+	PaymentBaseURL string
+	UserAgent      string
+	// AppToken is sent with every request unless Credentials is set.
+	AppToken string
+	// Credentials supplies the app token per request, allowing rotation.
+	Credentials   CredentialProvider
+	RequestHooks  []RequestHook
+	ResponseHooks []ResponseHook
+	// Strict turns negative Tuna codes in 200 responses into a *BusinessError.
+	Strict bool
+	// Retry enables retries of failed calls. Nil disables them.
+	Retry *RetryPolicy
+	// IdempotencyStore deduplicates Init, Capture and Cancel calls. Nil
+	// disables deduplication.
+	IdempotencyStore IdempotencyStore
+	// RateLimit throttles all calls of the client.
+	RateLimit *RateLimit
+	// EndpointRateLimits throttles groups of endpoints, keyed by endpoint name,
+	// e.g. "Payment.Status", or by API, i.e. "Token" or "Payment".
+	EndpointRateLimits map[string]RateLimit
+	// CircuitBreaker makes calls fail fast with ErrCircuitOpen while Tuna is
+	// failing. Nil disables it.
+	CircuitBreaker *CircuitBreakerConfig
+	// Middlewares wrap every call, the first one being the outermost.
+	Middlewares []Middleware
+	// Logger logs every call, with sensitive fields redacted. Nil disables
+	// logging.
+	Logger *slog.Logger
+	// TracerProvider starts a span for every call. Nil disables tracing.
+	TracerProvider trace.TracerProvider
+	// Propagator injects the trace context into outgoing requests when
+	// tracing is enabled. Defaults to W3C Trace Context.
+	Propagator propagation.TextMapPropagator
+	// Metrics receives measurements of every call. Nil disables metrics.
+	Metrics Metrics
+}
+
+// fileConfig is the part of Config that can be loaded from a file.
+type fileConfig struct {
+	Environment    string                 `json:"environment" yaml:"environment"`
+	Environments   map[string]Environment `json:"environments" yaml:"environments"`
+	BaseURL        string                 `json:"baseURL" yaml:"baseURL"`
+	TokenBaseURL   string                 `json:"tokenBaseURL" yaml:"tokenBaseURL"`
+	PaymentBaseURL string                 `json:"paymentBaseURL" yaml:"paymentBaseURL"`
+	UserAgent      string                 `json:"userAgent" yaml:"userAgent"`
+	AppToken       string                 `json:"appToken" yaml:"appToken"`
+	Strict         bool                   `json:"strict" yaml:"strict"`
+}
+
+// LoadConfigFile reads a Config from a YAML or JSON file, picked by extension.
+func LoadConfigFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var fc fileConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fc)
+	case ".json":
+		err = json.Unmarshal(data, &fc)
+	default:
+		return Config{}, fmt.Errorf("%w: unsupported config file %q", ErrInvalidConfig, path)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+
+	return Config{
+		Environment:    fc.Environment,
+		Environments:   fc.Environments,
+		BaseURL:        fc.BaseURL,
+		TokenBaseURL:   fc.TokenBaseURL,
+		PaymentBaseURL: fc.PaymentBaseURL,
+		UserAgent:      fc.UserAgent,
+		AppToken:       fc.AppToken,
+		Strict:         fc.Strict,
+	}, nil
+}
+
+// ConfigFromEnv reads a Config from the TUNA_* environment variables.
+func ConfigFromEnv() (Config, error) {
+	return applyEnv(Config{})
+}
+
+// LoadConfig reads a Config from the file at path, if path is not empty, and
+// overrides it with the TUNA_* environment variables that are set.
+func LoadConfig(path string) (Config, error) {
+	var conf Config
+	if path != "" {
+		var err error
+		if conf, err = LoadConfigFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	return applyEnv(conf)
+}
+
+func applyEnv(conf Config) (Config, error) {
+	if v, ok := os.LookupEnv(EnvEnvironment); ok {
+		conf.Environment = v
+	}
+	if v, ok := os.LookupEnv(EnvBaseURL); ok {
+		conf.BaseURL = v
+	}
+	if v, ok := os.LookupEnv(EnvTokenBaseURL); ok {
+		conf.TokenBaseURL = v
+	}
+	if v, ok := os.LookupEnv(EnvPaymentBaseURL); ok {
+		conf.PaymentBaseURL = v
+	}
+	if v, ok := os.LookupEnv(EnvUserAgent); ok {
+		conf.UserAgent = v
+	}
+	if v, ok := os.LookupEnv(EnvAppToken); ok {
+		conf.AppToken = v
+	}
+	if v, ok := os.LookupEnv(EnvStrict); ok {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, EnvStrict, err)
+		}
+		conf.Strict = strict
+	}
+
+	return conf, nil
+}
+
+// Validate checks that the config has an app token and a valid base URL for
+// both the token and the payment API.
+func (c Config) Validate() error {
+	if _, err := c.tokenBaseURL(); err != nil {
+		return err
+	}
+	if _, err := c.paymentBaseURL(); err != nil {
+		return err
+	}
+	return c.validateCredentials()
+}
+
+func (c Config) validateCredentials() error {
+	if c.AppToken == "" && c.Credentials == nil {
+		return fmt.Errorf("%w: app token or credentials are required", ErrInvalidConfig)
+	}
+	return nil
+}
+
+func (c Config) credentials() CredentialProvider {
+	if c.Credentials != nil {
+		return c.Credentials
+	}
+	return StaticCredentials(c.AppToken)
+}
+
+func (c Config) environment() (Environment, error) {
+	if env, ok := c.Environments[c.Environment]; ok {
+		return env, nil
+	}
+	if env, ok := environments[c.Environment]; ok {
+		return env, nil
+	}
+	return Environment{}, fmt.Errorf("%w: unknown environment %q", ErrInvalidConfig, c.Environment)
+}
+
+func (c Config) tokenBaseURL() (*url.URL, error) {
+	return c.baseURL(c.TokenBaseURL, func(env Environment) string { return env.TokenBaseURL })
+}
+
+func (c Config) paymentBaseURL() (*url.URL, error) {
+	return c.baseURL(c.PaymentBaseURL, func(env Environment) string { return env.PaymentBaseURL })
+}
+
+// baseURL returns override, BaseURL, or the host of the configured environment
+// picked by host, in that order, parsed and validated.
+func (c Config) baseURL(override string, host func(Environment) string) (*url.URL, error) {
+	raw := override
+	if raw == "" {
+		raw = c.BaseURL
+	}
+	if raw == "" {
+		if c.Environment == "" {
+			return nil, fmt.Errorf("%w: base URL or environment is required", ErrInvalidConfig)
+		}
+		env, err := c.environment()
+		if err != nil {
+			return nil, err
+		}
+		raw = host(env)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: base URL: %v", ErrInvalidConfig, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: base URL %q must be an absolute http(s) URL", ErrInvalidConfig, raw)
+	}
+
+	return u, nil
+}
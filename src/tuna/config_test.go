@@ -0,0 +1,89 @@
+package tuna
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	t.Run("should resolve hosts of a named environment", func(t *testing.T) {
+		conf := Config{Environment: "sandbox", AppToken: "token"}
+		require.NoError(t, conf.Validate())
+
+		token, err := conf.tokenBaseURL()
+		require.NoError(t, err)
+		assert.Equal(t, Sandbox.TokenBaseURL, token.String())
+
+		payment, err := conf.paymentBaseURL()
+		require.NoError(t, err)
+		assert.Equal(t, Sandbox.PaymentBaseURL, payment.String())
+	})
+
+	t.Run("should reject invalid configs", func(t *testing.T) {
+		for name, conf := range map[string]Config{
+			"missing base URL":    {AppToken: "token"},
+			"unknown environment": {Environment: "staging", AppToken: "token"},
+			"relative base URL":   {BaseURL: "tuna.test", AppToken: "token"},
+			"missing app token":   {BaseURL: "https://tuna.test"},
+		} {
+			assert.ErrorIs(t, conf.Validate(), ErrInvalidConfig, name)
+		}
+	})
+
+	t.Run("should fail to build clients from an invalid config", func(t *testing.T) {
+		tokenClient, err := NewTokenClient(nil, Config{BaseURL: "://bad", AppToken: "token"})
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Nil(t, tokenClient)
+
+		paymentClient, err := NewPaymentClient(nil, Config{Environment: "production"})
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.Nil(t, paymentClient)
+	})
+
+	t.Run("should load a YAML file with custom environments", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tuna.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+environment: staging
+environments:
+  staging:
+    tokenBaseURL: https://token.staging.test
+    paymentBaseURL: https://payment.staging.test
+userAgent: shop
+appToken: file-token
+strict: true
+`), 0o600))
+
+		conf, err := LoadConfigFile(path)
+		require.NoError(t, err)
+		require.NoError(t, conf.Validate())
+		assert.Equal(t, "shop", conf.UserAgent)
+		assert.True(t, conf.Strict)
+
+		payment, err := conf.paymentBaseURL()
+		require.NoError(t, err)
+		assert.Equal(t, "https://payment.staging.test", payment.String())
+	})
+
+	t.Run("should override a JSON file with environment variables", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tuna.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"environment": "sandbox", "appToken": "file-token"}`), 0o600))
+		t.Setenv(EnvAppToken, "env-token")
+		t.Setenv(EnvEnvironment, "production")
+
+		conf, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, "env-token", conf.AppToken)
+		assert.Equal(t, "production", conf.Environment)
+	})
+
+	t.Run("should reject an invalid strict flag", func(t *testing.T) {
+		t.Setenv(EnvStrict, "maybe")
+
+		_, err := ConfigFromEnv()
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
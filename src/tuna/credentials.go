@@ -0,0 +1,209 @@
+package tuna
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the app token sent with every request. It is
+// consulted per request, so credentials can rotate without rebuilding clients.
+type CredentialProvider interface {
+	AppToken(ctx context.Context) (string, error)
+}
+
+// CredentialRefresher is implemented by providers that can fetch fresh
+// credentials. A request rejected with 401 is retried once after Refresh.
+type CredentialRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// staleRefresher is implemented by refreshers that can tell whether the token
+// rejected with 401 has already been replaced by a concurrent refresh.
+type staleRefresher interface {
+	refreshStale(ctx context.Context, stale string) error
+}
+
+// refreshCredentials refreshes r after stale was rejected with 401.
+func refreshCredentials(ctx context.Context, r CredentialRefresher, stale string) error {
+	if sr, ok := r.(staleRefresher); ok {
+		return sr.refreshStale(ctx, stale)
+	}
+	return r.Refresh(ctx)
+}
+
+// StaticCredentials is a fixed app token.
+type StaticCredentials string
+
+func (s StaticCredentials) AppToken(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+// EnvCredentials reads the app token from an environment variable on every
+// request.
+type EnvCredentials struct {
+	// Name of the variable. Defaults to TUNA_APP_TOKEN.
+	Name string
+}
+
+func (e EnvCredentials) AppToken(ctx context.Context) (string, error) {
+	name := e.Name
+	if name == "" {
+		name = EnvAppToken
+	}
+
+	token := os.Getenv(name)
+	if token == "" {
+		return "", fmt.Errorf("%w: %s is not set", ErrInvalidConfig, name)
+	}
+	return token, nil
+}
+
+// FileCredentials reads the app token from a file and reloads it whenever the
+// file changes, e.g. when a secret mounted by Kubernetes is rotated.
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+func (f *FileCredentials) AppToken(ctx context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.token != "" && info.ModTime().Equal(f.modTime) {
+		return f.token, nil
+	}
+	return f.load(info.ModTime())
+}
+
+func (f *FileCredentials) Refresh(ctx context.Context) error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err = f.load(info.ModTime())
+	return err
+}
+
+func (f *FileCredentials) load(modTime time.Time) (string, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrInvalidConfig, f.path)
+	}
+
+	f.token = token
+	f.modTime = modTime
+	return token, nil
+}
+
+// CallbackCredentials fetches the app token through a callback, e.g. from a
+// secrets manager, and caches it for a TTL. Concurrent callers share a single
+// fetch, and the lock is not held while fetching.
+type CallbackCredentials struct {
+	fetch func(ctx context.Context) (string, error)
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	inflight  *tokenFetch
+}
+
+// tokenFetch is a call to the callback that other callers can wait on.
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// NewCallbackCredentials returns a provider calling fetch at most once per ttl.
+// A zero ttl caches the token until it is refreshed.
+func NewCallbackCredentials(fetch func(ctx context.Context) (string, error), ttl time.Duration) *CallbackCredentials {
+	return &CallbackCredentials{fetch: fetch, ttl: ttl}
+}
+
+func (c *CallbackCredentials) AppToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	if c.token != "" && (c.ttl == 0 || time.Now().Before(c.expiresAt)) {
+		token := c.token
+		c.mu.Unlock()
+		return token, nil
+	}
+	return c.load(ctx)
+}
+
+func (c *CallbackCredentials) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	_, err := c.load(ctx)
+	return err
+}
+
+// refreshStale refreshes the token unless stale, the token rejected with 401,
+// has already been replaced, so concurrent 401s cause a single fetch.
+func (c *CallbackCredentials) refreshStale(ctx context.Context, stale string) error {
+	c.mu.Lock()
+	if c.inflight == nil && c.token != "" && c.token != stale {
+		c.mu.Unlock()
+		return nil
+	}
+	_, err := c.load(ctx)
+	return err
+}
+
+// load fetches a new token, or waits for the fetch already in flight. It must
+// be called with c.mu held, and releases it. The fetch is shared, so it runs
+// detached from the cancellation of the caller that started it; each caller
+// only stops waiting when its own ctx ends.
+func (c *CallbackCredentials) load(ctx context.Context) (string, error) {
+	f := c.inflight
+	if f == nil {
+		f = &tokenFetch{done: make(chan struct{})}
+		c.inflight = f
+		go c.run(context.WithoutCancel(ctx), f)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (c *CallbackCredentials) run(ctx context.Context, f *tokenFetch) {
+	f.token, f.err = c.fetch(ctx)
+
+	c.mu.Lock()
+	c.inflight = nil
+	if f.err == nil {
+		c.token = f.token
+		c.expiresAt = time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(f.done)
+}
@@ -0,0 +1,184 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentials(t *testing.T) {
+	t.Run("should retry once with refreshed credentials on 401", func(t *testing.T) {
+		var sent []string
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			token := req.Header.Get("x-tuna-apptoken")
+			sent = append(sent, token)
+			code := http.StatusOK
+			if token != "fresh" {
+				code = http.StatusUnauthorized
+			}
+			return &http.Response{
+				StatusCode: code,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+
+		tokens := []string{"stale", "fresh"}
+		credentials := NewCallbackCredentials(func(ctx context.Context) (string, error) {
+			token := tokens[0]
+			tokens = tokens[1:]
+			return token, nil
+		}, 0)
+		api := newTestPaymentClient(t, client, Config{AppToken: "unused", Credentials: credentials})
+
+		_, err := api.Init(InitRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"stale", "fresh"}, sent)
+	})
+
+	t.Run("should not retry more than once on 401", func(t *testing.T) {
+		var calls int
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		credentials := NewCallbackCredentials(func(ctx context.Context) (string, error) {
+			return "token", nil
+		}, time.Minute)
+		api := newTestPaymentClient(t, client, Config{Credentials: credentials})
+
+		_, err := api.Init(InitRequest{})
+		var apiErr *APIError
+		assert.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should refresh once for concurrent 401s", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			code := http.StatusOK
+			if req.Header.Get("x-tuna-apptoken") != "fresh" {
+				code = http.StatusUnauthorized
+			}
+			return &http.Response{
+				StatusCode: code,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+
+		var fetches int32
+		release := make(chan struct{})
+		credentials := NewCallbackCredentials(func(ctx context.Context) (string, error) {
+			if atomic.AddInt32(&fetches, 1) == 1 {
+				return "stale", nil
+			}
+			<-release
+			return "fresh", nil
+		}, 0)
+		api := newTestPaymentClient(t, client, Config{Credentials: credentials})
+
+		token, err := credentials.AppToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "stale", token)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 10)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = api.Status(StatusRequest{})
+			}(i)
+		}
+
+		// The cached token stays available while the refresh is blocked.
+		time.Sleep(20 * time.Millisecond)
+		token, err = credentials.AppToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "stale", token)
+
+		close(release)
+		wg.Wait()
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+	})
+
+	t.Run("should keep the shared fetch when its first caller gives up", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		credentials := NewCallbackCredentials(func(ctx context.Context) (string, error) {
+			close(started)
+			select {
+			case <-release:
+				return "fresh", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() {
+			_, err := credentials.AppToken(ctx)
+			first <- err
+		}()
+		<-started
+
+		second := make(chan string, 1)
+		go func() {
+			token, _ := credentials.AppToken(context.Background())
+			second <- token
+		}()
+
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+
+		close(release)
+		assert.Equal(t, "fresh", <-second)
+	})
+
+	t.Run("should reload a rotated token file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app-token")
+		require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+		credentials := NewFileCredentials(path)
+
+		token, err := credentials.AppToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "first", token)
+
+		require.NoError(t, os.WriteFile(path, []byte("second\n"), 0o600))
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+		token, err = credentials.AppToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "second", token)
+	})
+
+	t.Run("should read the token from the environment", func(t *testing.T) {
+		t.Setenv("SHOP_TUNA_TOKEN", "env-token")
+
+		token, err := EnvCredentials{Name: "SHOP_TUNA_TOKEN"}.AppToken(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "env-token", token)
+
+		_, err = EnvCredentials{Name: "SHOP_TUNA_MISSING"}.AppToken(context.Background())
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
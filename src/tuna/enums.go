@@ -0,0 +1,60 @@
+package tuna
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// PaymentBehavior tells how a payment option completes, and so which statuses
+// to expect from Init. Values not listed below are kept, see unmarshalEnum.
+type PaymentBehavior string
+
+const (
+	// PaymentBehaviorDirect options, such as credit cards, are authorized or
+	// denied while answering Init.
+	PaymentBehaviorDirect PaymentBehavior = "0"
+	// PaymentBehaviorRedirect options answer Init with PaymentStatusPending
+	// and a redirect URL, then go on through Continue.
+	PaymentBehaviorRedirect PaymentBehavior = "1"
+	// PaymentBehaviorAsync options, such as PIX or boleto, answer Init with
+	// PaymentStatusPending and settle later; see WaitForFinalStatus.
+	PaymentBehaviorAsync PaymentBehavior = "2"
+)
+
+func (b *PaymentBehavior) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, (*string)(b))
+}
+
+// DeleteStatus is the status reported by DeleteCardToken. Values not listed
+// below are kept, see unmarshalEnum.
+type DeleteStatus string
+
+const (
+	DeleteStatusDeleted DeleteStatus = "deleted"
+)
+
+func (s *DeleteStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, (*string)(s))
+}
+
+// unmarshalEnum reads an enum value sent either as a JSON string or as a
+// number, keeping unknown values. A number is kept as its JSON literal, so 1
+// matches a constant "1"; like any other value, it is written back as a JSON
+// string.
+func unmarshalEnum(data []byte, v *string) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		return json.Unmarshal(data, v)
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("tuna: invalid enum value %s", data)
+	}
+	*v = n.String()
+	return nil
+}
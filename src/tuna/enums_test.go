@@ -0,0 +1,56 @@
+package tuna
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnums(t *testing.T) {
+	t.Run("should decode strings, numbers and unknown values", func(t *testing.T) {
+		var res InitResponse
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"status": 1,
+			"methods": [{"status": "2"}, {"status": "Z"}, {"status": null}]
+		}`), &res))
+
+		assert.Equal(t, PaymentStatusAuthorized, res.Status)
+		assert.Equal(t, PaymentStatusCaptured, res.Methods[0].Status)
+		assert.Equal(t, PaymentStatus("Z"), res.Methods[1].Status)
+		assert.Equal(t, PaymentStatus(""), res.Methods[2].Status)
+
+		var option PaymentOption
+		require.NoError(t, json.Unmarshal([]byte(`{"paymentBehavior": 2}`), &option))
+		assert.Equal(t, PaymentBehaviorAsync, option.PaymentBehavior)
+	})
+
+	t.Run("should encode unknown values", func(t *testing.T) {
+		data, err := json.Marshal(DeleteCardTokenResponse{Status: "gone"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status": "gone", "code": 0, "message": ""}`, string(data))
+	})
+
+	t.Run("should encode every value as a string", func(t *testing.T) {
+		for in, out := range map[string]string{`1`: `"1"`, `7`: `"7"`, `"7"`: `"7"`, `"Z"`: `"Z"`, `""`: `""`} {
+			var status PaymentStatus
+			require.NoError(t, json.Unmarshal([]byte(in), &status))
+			data, err := json.Marshal(status)
+			require.NoError(t, err)
+			assert.Equal(t, out, string(data), in)
+		}
+
+		var b PaymentBehavior
+		require.NoError(t, json.Unmarshal([]byte(`2`), &b))
+		assert.Equal(t, PaymentBehaviorAsync, b)
+		data, err := json.Marshal(b)
+		require.NoError(t, err)
+		assert.Equal(t, `"2"`, string(data))
+	})
+
+	t.Run("should reject other JSON types", func(t *testing.T) {
+		var status PaymentStatus
+		assert.Error(t, json.Unmarshal([]byte(`{}`), &status))
+	})
+}
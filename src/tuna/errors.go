@@ -0,0 +1,242 @@
+package tuna
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const maxErrorBodySize = 1 << 20
+
+// APIError is returned when Tuna answers with a non-200 status code. It carries
+// the RFC 7807 problem details and Tuna Message found in the body, if any.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Title      string
+	Detail     string
+	TraceID    string
+	Errors     map[string][]string
+	Message    Message
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "an error occurred, status code %v", e.StatusCode)
+
+	switch {
+	case e.Title != "":
+		fmt.Fprintf(&b, ": %s", e.Title)
+	case e.Message.Message != "":
+		fmt.Fprintf(&b, ": %s", e.Message.Message)
+	}
+
+	if len(e.Errors) > 0 {
+		fields := make([]string, 0, len(e.Errors))
+		for field := range e.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		details := make([]string, 0, len(fields))
+		for _, field := range fields {
+			details = append(details, fmt.Sprintf("%s: %s", field, strings.Join(e.Errors[field], ", ")))
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, "; "))
+	}
+
+	if e.TraceID != "" {
+		fmt.Fprintf(&b, " [trace %s]", e.TraceID)
+	}
+
+	return b.String()
+}
+
+type errorBody struct {
+	Type    string              `json:"type"`
+	Title   string              `json:"title"`
+	Detail  string              `json:"detail"`
+	TraceID string              `json:"traceId"`
+	Errors  map[string][]string `json:"errors"`
+	Code    int                 `json:"code"`
+	Message json.RawMessage     `json:"message"`
+}
+
+// newAPIError reads the body of resp and decodes whatever problem details or
+// Tuna Message it contains. Bodies that are not JSON are kept only as Body.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+	apiErr.Body = body
+
+	var eb errorBody
+	if err := json.Unmarshal(body, &eb); err != nil {
+		return apiErr
+	}
+
+	apiErr.Type = eb.Type
+	apiErr.Title = eb.Title
+	apiErr.Detail = eb.Detail
+	apiErr.TraceID = eb.TraceID
+	apiErr.Errors = eb.Errors
+
+	var text string
+	switch {
+	case json.Unmarshal(eb.Message, &apiErr.Message) == nil:
+	case json.Unmarshal(eb.Message, &text) == nil:
+		apiErr.Message = Message{Code: eb.Code, Message: text}
+	}
+
+	return apiErr
+}
+
+// Sentinel errors returned in strict mode, wrapped in a *BusinessError, when
+// Tuna answers with HTTP 200 but reports a failure in the body.
+var (
+	ErrInvalidSession = errors.New("tuna: invalid session")
+	ErrInvalidToken   = errors.New("tuna: invalid token")
+	ErrDeclined       = errors.New("tuna: payment declined")
+	ErrRequestFailed  = errors.New("tuna: request failed")
+)
+
+// Errors returned by IdempotencyStore implementations.
+var (
+	ErrIdempotencyKeyInFlight = errors.New("tuna: a call with the same idempotency key is in flight")
+	ErrIdempotencyKeyUnknown  = errors.New("tuna: idempotency key was not reserved")
+)
+
+// BusinessCodes maps the negative codes Tuna reports in the "code" field of
+// token responses and in Message blocks of payment responses to sentinel
+// errors:
+//
+//	-1  ErrInvalidSession  session object is invalid or expired
+//	-2  ErrInvalidToken    card token is invalid, expired or not in the session
+//	-3  ErrDeclined        payment was declined by the acquirer or anti-fraud
+//
+// Any other negative code maps to ErrRequestFailed.
+var BusinessCodes = map[int]error{
+	-1: ErrInvalidSession,
+	-2: ErrInvalidToken,
+	-3: ErrDeclined,
+}
+
+// BusinessError is returned in strict mode when a 200 response carries a
+// negative Tuna code. It unwraps to one of the sentinel errors above.
+type BusinessError struct {
+	Endpoint Endpoint
+	Message  Message
+	Err      error
+}
+
+func (e *BusinessError) Error() string {
+	return fmt.Sprintf("%v: %s returned code %v: %s", e.Err, e.Endpoint, e.Message.Code, e.Message.Message)
+}
+
+func (e *BusinessError) Unwrap() error {
+	return e.Err
+}
+
+// messageCarrier is implemented by responses that report a Tuna outcome code.
+type messageCarrier interface {
+	tunaMessages() []Message
+}
+
+// checkBusinessError returns a *BusinessError for the first failed Message of
+// out, if out reports any.
+func checkBusinessError(endpointName Endpoint, out interface{}) error {
+	mc, ok := out.(messageCarrier)
+	if !ok {
+		return nil
+	}
+
+	for _, msg := range mc.tunaMessages() {
+		if msg.Code >= 0 {
+			continue
+		}
+
+		err, ok := BusinessCodes[msg.Code]
+		if !ok {
+			err = ErrRequestFailed
+		}
+
+		return &BusinessError{Endpoint: endpointName, Message: msg, Err: err}
+	}
+
+	return nil
+}
+
+func methodMessages(msg Message, methods []Method) []Message {
+	msgs := []Message{msg}
+	for _, m := range methods {
+		msgs = append(msgs, m.Message)
+	}
+	return msgs
+}
+
+func (r *NewSessionResponse) tunaMessages() []Message {
+	return []Message{{Code: r.Code, Message: r.Message}}
+}
+
+func (r *GenerateCardTokenResponse) tunaMessages() []Message {
+	return []Message{{Code: r.Code, Message: r.Message}}
+}
+
+func (r *ListTokensResponse) tunaMessages() []Message {
+	return []Message{{Code: r.Code, Message: r.Message}}
+}
+
+func (r *DeleteCardTokenResponse) tunaMessages() []Message {
+	return []Message{{Code: r.Code, Message: r.Message}}
+}
+
+func (r *BindCVVResponse) tunaMessages() []Message {
+	return []Message{{Code: r.Code, Message: r.Message}}
+}
+
+func (r *InitResponse) tunaMessages() []Message {
+	return methodMessages(r.Message, r.Methods)
+}
+
+func (r *CancelResponse) tunaMessages() []Message {
+	return methodMessages(r.Message, r.Methods)
+}
+
+func (r *CancelItemResponse) tunaMessages() []Message {
+	msgs := []Message{r.Message}
+	for _, item := range r.Items {
+		msgs = append(msgs, item.Message)
+	}
+	return msgs
+}
+
+func (r *CaptureResponse) tunaMessages() []Message {
+	return methodMessages(r.Message, r.Methods)
+}
+
+func (r *ContinueResponse) tunaMessages() []Message {
+	return []Message{r.Message}
+}
+
+// The methods of a StatusResponse describe the payment rather than the call,
+// so a declined method does not fail the Status call itself.
+func (r *StatusResponse) tunaMessages() []Message {
+	return []Message{r.Message}
+}
+
+func (r *OptionsResponse) tunaMessages() []Message {
+	return []Message{r.Message}
+}
+
+func (r *FunctionResponse) tunaMessages() []Message {
+	return []Message{r.Message}
+}
@@ -0,0 +1,196 @@
+package tuna
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyStore tracks in-flight and completed idempotent calls so that a
+// duplicate gets the original response instead of reaching Tuna twice.
+type IdempotencyStore interface {
+	// Begin reserves key for a new call. If a call with the same key has
+	// already completed, Begin returns its response body and found set to
+	// true. If one is still in flight, Begin waits for it or returns an error
+	// such as ErrIdempotencyKeyInFlight.
+	Begin(ctx context.Context, key string) (body []byte, found bool, err error)
+	// Complete stores the response body of the call holding key. If it fails,
+	// the call still returns the response Tuna sent and key is aborted, so a
+	// later duplicate reaches Tuna again instead of waiting forever.
+	Complete(ctx context.Context, key string, body []byte) error
+	// Abort releases key after a failed call so it can be tried again.
+	Abort(ctx context.Context, key string) error
+}
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a context that makes Init, Capture and Cancel
+// calls use key instead of one derived from the request.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// idempotentRequest is implemented by requests that can derive their own
+// idempotency key.
+type idempotentRequest interface {
+	idempotencyKey() string
+}
+
+// idempotencyKey returns the key of in. Only mutating requests have one, so
+// reads such as Status are never served from the store.
+func (t *transport) idempotencyKey(ctx context.Context, in interface{}) string {
+	r, ok := in.(idempotentRequest)
+	if !ok {
+		return ""
+	}
+
+	if key, ok := ctx.Value(idempotencyKeyContextKey{}).(string); ok && key != "" {
+		return key
+	}
+
+	if t.idempotency == nil {
+		return ""
+	}
+
+	return r.idempotencyKey()
+}
+
+// Init calls are deduplicated by PartnerUniqueID alone, so a double submit of
+// the same order never starts two payments.
+func (r InitRequest) idempotencyKey() string {
+	return r.PartnerUniqueID
+}
+
+// Capture and Cancel may legitimately be called more than once for the same
+// order with different amounts, so their keys also cover the request body.
+func (r CaptureRequest) idempotencyKey() string {
+	return digestKey(r.PartnerUniqueID, r)
+}
+
+func (r CancelRequest) idempotencyKey() string {
+	return digestKey(r.PartnerUniqueID, r)
+}
+
+func digestKey(partnerUniqueID string, request interface{}) string {
+	if partnerUniqueID == "" {
+		return ""
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(body)
+	return partnerUniqueID + ":" + hex.EncodeToString(sum[:8])
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. A duplicate of an
+// in-flight call waits for it to finish. Expired calls are swept from Begin,
+// at most once per ttl or per minute, whichever is shorter.
+type MemoryIdempotencyStore struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[string]*idempotencyEntry
+	nextSweep time.Time
+}
+
+type idempotencyEntry struct {
+	done      chan struct{}
+	completed bool
+	body      []byte
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore returns a store that remembers completed calls for
+// ttl, or 24 hours if ttl is zero.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Begin(ctx context.Context, key string) ([]byte, bool, error) {
+	for {
+		s.mu.Lock()
+		s.sweep(time.Now())
+		entry, ok := s.entries[key]
+		if ok && entry.completed && time.Now().After(entry.expiresAt) {
+			delete(s.entries, key)
+			ok = false
+		}
+		if !ok {
+			s.entries[key] = &idempotencyEntry{done: make(chan struct{})}
+			s.mu.Unlock()
+			return nil, false, nil
+		}
+		if entry.completed {
+			s.mu.Unlock()
+			return entry.body, true, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-entry.done:
+		}
+	}
+}
+
+// sweep deletes the expired calls if it is time to. It must be called with
+// s.mu held.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(min(s.ttl, time.Minute))
+
+	for key, entry := range s.entries {
+		if entry.completed && now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.completed {
+		return ErrIdempotencyKeyUnknown
+	}
+
+	entry.completed = true
+	entry.body = body
+	entry.expiresAt = time.Now().Add(s.ttl)
+	close(entry.done)
+
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Abort(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.completed {
+		return ErrIdempotencyKeyUnknown
+	}
+
+	delete(s.entries, key)
+	close(entry.done)
+
+	return nil
+}
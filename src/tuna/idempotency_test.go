@@ -0,0 +1,195 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	t.Run("should return the original response for a duplicate Init", func(t *testing.T) {
+		var calls int
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			calls++
+			assert.Equal(t, "order-1", req.Header.Get("Idempotency-Key"))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"paymentKey": "key-1"}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{IdempotencyStore: NewMemoryIdempotencyStore(0)})
+
+		first, err := api.Init(InitRequest{PartnerUniqueID: "order-1"})
+		assert.NoError(t, err)
+		second, err := api.Init(InitRequest{PartnerUniqueID: "order-1"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("should send the call again after a failure", func(t *testing.T) {
+		var calls int
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			calls++
+			code := http.StatusOK
+			if calls == 1 {
+				code = http.StatusBadRequest
+			}
+			return &http.Response{
+				StatusCode: code,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{IdempotencyStore: NewMemoryIdempotencyStore(0)})
+
+		_, err := api.Capture(CaptureRequest{PartnerUniqueID: "order-1", Amount: 10})
+		assert.Error(t, err)
+		_, err = api.Capture(CaptureRequest{PartnerUniqueID: "order-1", Amount: 10})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should return the response when it cannot be stored", func(t *testing.T) {
+		var calls int
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			calls++
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"paymentKey": "key-1"}`)),
+				Header:     make(http.Header),
+			}
+		})
+		store := &failingCompleteStore{MemoryIdempotencyStore: NewMemoryIdempotencyStore(0)}
+		api := newTestPaymentClient(t, client, Config{IdempotencyStore: store})
+
+		res, err := api.Init(InitRequest{PartnerUniqueID: "order-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", res.PaymentKey)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = api.InitWithContext(ctx, InitRequest{PartnerUniqueID: "order-1"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should use the key from the context", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			assert.Equal(t, "custom", req.Header.Get("Idempotency-Key"))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{})
+
+		_, err := api.CancelWithContext(WithIdempotencyKey(context.Background(), "custom"), CancelRequest{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestIdempotency_Reads(t *testing.T) {
+	t.Run("should not apply the context key to reads", func(t *testing.T) {
+		var calls int
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			calls++
+			assert.Empty(t, req.Header.Get("Idempotency-Key"))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"status": "%d"}`, calls))),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{IdempotencyStore: NewMemoryIdempotencyStore(0)})
+		ctx := WithIdempotencyKey(context.Background(), "custom")
+
+		first, err := api.StatusWithContext(ctx, StatusRequest{PartnerUniqueID: "order-1"})
+		require.NoError(t, err)
+		second, err := api.StatusWithContext(ctx, StatusRequest{PartnerUniqueID: "order-1"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, PaymentStatus("1"), first.Status)
+		assert.Equal(t, PaymentStatus("2"), second.Status)
+	})
+}
+
+// failingCompleteStore is a MemoryIdempotencyStore that fails to store
+// responses.
+type failingCompleteStore struct {
+	*MemoryIdempotencyStore
+}
+
+func (s *failingCompleteStore) Complete(ctx context.Context, key string, body []byte) error {
+	return fmt.Errorf("store unavailable")
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	t.Run("should sweep expired calls", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore(10 * time.Millisecond)
+		ctx := context.Background()
+
+		for _, key := range []string{"order-1", "order-2"} {
+			_, _, err := store.Begin(ctx, key)
+			require.NoError(t, err)
+			require.NoError(t, store.Complete(ctx, key, []byte("body")))
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		_, _, err := store.Begin(ctx, "order-3")
+		require.NoError(t, err)
+
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		assert.Len(t, store.entries, 1)
+		assert.Contains(t, store.entries, "order-3")
+	})
+
+	t.Run("should make a duplicate wait for the in-flight call", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore(time.Minute)
+		ctx := context.Background()
+
+		_, found, err := store.Begin(ctx, "key")
+		assert.NoError(t, err)
+		assert.False(t, found)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, found, err := store.Begin(ctx, "key")
+			assert.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, []byte("body"), body)
+		}()
+
+		assert.NoError(t, store.Complete(ctx, "key", []byte("body")))
+		wg.Wait()
+	})
+
+	t.Run("should stop waiting when the context is done", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore(time.Minute)
+
+		_, _, err := store.Begin(context.Background(), "key")
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		_, _, err = store.Begin(ctx, "key")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,376 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrIllegalOperation = errors.New("tuna: operation not allowed in the current payment status")
+	ErrAmountExceeded   = errors.New("tuna: amount exceeds the authorized amount")
+)
+
+// OperationError is returned by Payment when an operation is rejected locally,
+// before any call to Tuna.
+type OperationError struct {
+	Operation Endpoint
+	Status    PaymentStatus
+	Err       error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("%s in status %s: %s", e.Operation, e.Status, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// allowedOperations lists the operations Payment accepts in each status,
+// following the lifecycle documented on PaymentStatus. Capture is further
+// limited to payments with an amount left to capture.
+var allowedOperations = map[PaymentStatus][]Endpoint{
+	PaymentStatusStarted:    {EndpointCancel},
+	PaymentStatusPending:    {EndpointContinue, EndpointCancel},
+	PaymentStatusAuthorized: {EndpointCapture, EndpointCancel, EndpointCancelItem},
+	PaymentStatusCaptured:   {EndpointCapture, EndpointCancel, EndpointCancelItem},
+}
+
+// Payment tracks one payment through its lifecycle. Each operation is checked
+// against the current status before it is sent, and the status is updated
+// from the response. A Payment is safe for concurrent use; operations run one
+// at a time.
+type Payment struct {
+	mu  sync.Mutex
+	api PaymentAPI
+
+	partnerUniqueID string
+	paymentKey      string
+	date            time.Time
+	status          PaymentStatus
+	amount          int
+	captured        int
+	cancelled       int
+	methods         []Method
+	redirectURL     string
+}
+
+// StartPayment sends request through Init and returns the resulting Payment.
+// When Tuna answers with a failed message, e.g. a declined card, both the
+// Payment and a *BusinessError are returned.
+func StartPayment(ctx context.Context, api PaymentAPI, request InitRequest) (*Payment, error) {
+	res, err := api.InitWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Payment{
+		api:             api,
+		partnerUniqueID: request.PartnerUniqueID,
+		paymentKey:      res.PaymentKey,
+		date:            res.PaymentDate.Time,
+		status:          res.Status,
+		methods:         res.Methods,
+		redirectURL:     res.RedirectInfo.Url,
+	}
+	for _, m := range request.PaymentData.PaymentMethods {
+		p.amount += m.Amount
+	}
+	if p.status == "" {
+		p.status = PaymentStatusStarted
+	}
+
+	return p, checkBusinessError(EndpointInit, res)
+}
+
+// LoadPayment returns the Payment described by the Status of request, e.g. to
+// go on with a payment started by another process.
+func LoadPayment(ctx context.Context, api PaymentAPI, request StatusRequest) (*Payment, error) {
+	p := &Payment{api: api, partnerUniqueID: request.PartnerUniqueID, paymentKey: request.PaymentKey, date: request.PaymentDate}
+	if err := p.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// StartPayment starts a Payment through the payment API of s.
+func (s *PaymentAdapter) StartPayment(ctx context.Context, request InitRequest) (*Payment, error) {
+	return StartPayment(ctx, s.paymentAPI, request)
+}
+
+func (p *Payment) PartnerUniqueID() string {
+	return p.partnerUniqueID
+}
+
+func (p *Payment) PaymentKey() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paymentKey
+}
+
+func (p *Payment) Status() PaymentStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// Amount returns the total amount of the payment methods.
+func (p *Payment) Amount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.amount
+}
+
+// CapturedAmount returns the amount captured so far.
+func (p *Payment) CapturedAmount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.captured
+}
+
+// CancelledAmount returns the amount of the items cancelled through
+// CancelItems, which can no longer be captured.
+func (p *Payment) CancelledAmount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cancelled
+}
+
+// left is the amount that can still be captured.
+func (p *Payment) left() int {
+	return p.amount - p.cancelled - p.captured
+}
+
+// RedirectURL returns where to send the customer while the payment is pending.
+func (p *Payment) RedirectURL() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.redirectURL
+}
+
+func (p *Payment) Methods() []Method {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Method(nil), p.methods...)
+}
+
+// Can reports whether operation is allowed in the current status.
+func (p *Payment) Can(operation Endpoint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.check(operation) == nil
+}
+
+func (p *Payment) check(operation Endpoint) error {
+	for _, allowed := range allowedOperations[p.status] {
+		if allowed == operation && (operation != EndpointCapture || p.left() > 0) {
+			return nil
+		}
+	}
+	return &OperationError{Operation: operation, Status: p.status, Err: ErrIllegalOperation}
+}
+
+// Continue resumes a pending payment, then refreshes its status.
+func (p *Payment) Continue(ctx context.Context, request ContinueRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.check(EndpointContinue); err != nil {
+		return err
+	}
+
+	request.PartnerUniqueID = p.partnerUniqueID
+	request.PaymentKey = p.paymentKey
+	if request.PaymentDate.IsZero() {
+		date, err := p.paymentDate(ctx)
+		if err != nil {
+			return err
+		}
+		request.PaymentDate = date
+	}
+
+	res, err := p.api.ContinueWithContext(ctx, request)
+	if err != nil {
+		return err
+	}
+	if err := checkBusinessError(EndpointContinue, res); err != nil {
+		return err
+	}
+
+	return p.refresh(ctx)
+}
+
+// Capture captures amount of an authorized payment, or all of what is left
+// when amount is zero. Cancelled items are not capturable. After a partial
+// capture the payment is Captured and can be captured again until nothing is
+// left.
+func (p *Payment) Capture(ctx context.Context, amount int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.check(EndpointCapture); err != nil {
+		return err
+	}
+
+	left := p.left()
+	if amount == 0 {
+		amount = left
+	}
+	if amount < 0 || amount > left {
+		return &OperationError{Operation: EndpointCapture, Status: p.status, Err: ErrAmountExceeded}
+	}
+
+	date, err := p.paymentDate(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.api.CaptureWithContext(ctx, CaptureRequest{
+		Amount:          amount,
+		PaymentKey:      p.paymentKey,
+		PartnerUniqueID: p.partnerUniqueID,
+		PaymentDate:     date,
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkBusinessError(EndpointCapture, res); err != nil {
+		return err
+	}
+
+	p.captured += amount
+	p.apply(res.Status, res.Methods)
+	return nil
+}
+
+// Cancel cancels the whole payment, which refunds it once captured.
+func (p *Payment) Cancel(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.check(EndpointCancel); err != nil {
+		return err
+	}
+
+	date, err := p.paymentDate(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.api.CancelWithContext(ctx, CancelRequest{
+		PartnerUniqueID: p.partnerUniqueID,
+		PaymentDate:     date.Format("2006-01-02"),
+		CancelAll:       true,
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkBusinessError(EndpointCancel, res); err != nil {
+		return err
+	}
+
+	p.apply(res.Status, res.Methods)
+	return nil
+}
+
+// CancelItems cancels some items of an authorized or captured payment, then
+// refreshes its status to learn the cancelled amount, which is no longer
+// capturable. The payment keeps its status.
+func (p *Payment) CancelItems(ctx context.Context, items []ItemDetail) (*CancelItemResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.check(EndpointCancelItem); err != nil {
+		return nil, err
+	}
+
+	date, err := p.paymentDate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.api.CancelItemWithContext(ctx, CancelItemRequest{
+		PartnerUniqueID: p.partnerUniqueID,
+		PaymentDate:     date.Format("2006-01-02"),
+		ItemsDetail:     items,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBusinessError(EndpointCancelItem, res); err != nil {
+		return nil, err
+	}
+
+	return res, p.refresh(ctx)
+}
+
+// Refresh reloads the status of the payment from Tuna.
+func (p *Payment) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refresh(ctx)
+}
+
+// paymentDate returns the date Tuna gave the payment, refreshing the status
+// when it is not known yet.
+func (p *Payment) paymentDate(ctx context.Context) (time.Time, error) {
+	if p.date.IsZero() {
+		if err := p.refresh(ctx); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return p.date, nil
+}
+
+func (p *Payment) refresh(ctx context.Context) error {
+	res, err := p.api.StatusWithContext(ctx, StatusRequest{
+		PartnerUniqueID: p.partnerUniqueID,
+		PaymentKey:      p.paymentKey,
+		PaymentDate:     p.date,
+	})
+	if err != nil {
+		return err
+	}
+	if err := checkBusinessError(EndpointStatus, res); err != nil {
+		return err
+	}
+
+	if res.PaymentKey != "" {
+		p.paymentKey = res.PaymentKey
+	}
+	if p.date.IsZero() {
+		p.date = res.PaymentDate.Time
+	}
+	if res.Amount > 0 {
+		p.amount = res.Amount
+	}
+	if len(res.Methods) > 0 {
+		p.captured = 0
+		for _, m := range res.Methods {
+			p.captured += m.CapturedAmount
+		}
+	}
+	if len(res.Items) > 0 {
+		p.cancelled = 0
+		for _, item := range res.Items {
+			p.cancelled += item.Amount * item.CancelledQuantity
+		}
+	}
+	if res.Status != PaymentStatusPending {
+		p.redirectURL = ""
+	}
+	p.apply(res.Status, nil)
+	return nil
+}
+
+func (p *Payment) apply(status PaymentStatus, methods []Method) {
+	if status != "" {
+		p.status = status
+	}
+	if methods != nil {
+		p.methods = methods
+	}
+}
@@ -0,0 +1,193 @@
+package tuna_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+	"github.com/rodrigodev/tuna_go/src/tuna/tunamock"
+	"github.com/rodrigodev/tuna_go/src/tuna/tunatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayment(t *testing.T) {
+	ctx := context.Background()
+	initRequest := func(partnerUniqueID string) tuna.InitRequest {
+		return tuna.InitRequest{
+			PartnerUniqueID: partnerUniqueID,
+			PaymentData: tuna.PaymentData{PaymentMethods: []tuna.PaymentMethods{{
+				PaymentMethodType: "1",
+				Amount:            100,
+			}}},
+		}
+	}
+	newService := func(t *testing.T) (*tunatest.Server, *tuna.PaymentAdapter) {
+		s := tunatest.NewServer()
+		t.Cleanup(s.Close)
+		client, err := tuna.NewClient(s.Client(), s.Config())
+		require.NoError(t, err)
+		return s, client.Service()
+	}
+
+	t.Run("should capture and refund an authorized payment", func(t *testing.T) {
+		s, service := newService(t)
+
+		p, err := service.StartPayment(ctx, initRequest("order-1"))
+		require.NoError(t, err)
+		assert.Equal(t, tuna.PaymentStatusAuthorized, p.Status())
+		assert.NotEmpty(t, p.PaymentKey())
+		assert.False(t, p.Can(tuna.EndpointContinue))
+
+		err = p.Capture(ctx, 150)
+		assert.ErrorIs(t, err, tuna.ErrAmountExceeded)
+		assert.Equal(t, 0, s.Calls(tuna.EndpointCapture))
+
+		require.NoError(t, p.Capture(ctx, 60))
+		assert.Equal(t, tuna.PaymentStatusCaptured, p.Status())
+		assert.Equal(t, 60, p.CapturedAmount())
+
+		require.NoError(t, p.Cancel(ctx))
+		assert.Equal(t, tuna.PaymentStatusRefunded, p.Status())
+
+		err = p.Cancel(ctx)
+		var oe *tuna.OperationError
+		require.ErrorAs(t, err, &oe)
+		assert.Equal(t, tuna.EndpointCancel, oe.Operation)
+		assert.Equal(t, tuna.PaymentStatusRefunded, oe.Status)
+		assert.ErrorIs(t, err, tuna.ErrIllegalOperation)
+	})
+
+	t.Run("should not capture a cancelled payment", func(t *testing.T) {
+		s, service := newService(t)
+
+		p, err := service.StartPayment(ctx, initRequest("order-1"))
+		require.NoError(t, err)
+		require.NoError(t, p.Cancel(ctx))
+		assert.Equal(t, tuna.PaymentStatusCancelled, p.Status())
+
+		assert.ErrorIs(t, p.Capture(ctx, 0), tuna.ErrIllegalOperation)
+		assert.Equal(t, 0, s.Calls(tuna.EndpointCapture))
+	})
+
+	t.Run("should continue a pending payment", func(t *testing.T) {
+		s, service := newService(t)
+		s.SetOutcome(tunatest.Redirect3DS)
+
+		p, err := service.StartPayment(ctx, initRequest("order-1"))
+		require.NoError(t, err)
+		assert.Equal(t, tuna.PaymentStatusPending, p.Status())
+		assert.NotEmpty(t, p.RedirectURL())
+		assert.ErrorIs(t, p.Capture(ctx, 0), tuna.ErrIllegalOperation)
+
+		require.NoError(t, p.Continue(ctx, tuna.ContinueRequest{}))
+		assert.Equal(t, tuna.PaymentStatusAuthorized, p.Status())
+		assert.Empty(t, p.RedirectURL())
+
+		require.NoError(t, p.Capture(ctx, 0))
+		assert.Equal(t, 100, p.CapturedAmount())
+	})
+
+	t.Run("should return a declined payment with its error", func(t *testing.T) {
+		s, service := newService(t)
+		s.SetOutcome(tunatest.Decline)
+
+		p, err := service.StartPayment(ctx, initRequest("order-1"))
+		assert.ErrorIs(t, err, tuna.ErrDeclined)
+		require.NotNil(t, p)
+		assert.Equal(t, tuna.PaymentStatusDenied, p.Status())
+		assert.False(t, p.Can(tuna.EndpointCancel))
+	})
+
+	t.Run("should load a payment from its status", func(t *testing.T) {
+		s, service := newService(t)
+		client, err := tuna.NewPaymentClient(s.Client(), s.Config())
+		require.NoError(t, err)
+
+		_, err = service.StartPayment(ctx, initRequest("order-1"))
+		require.NoError(t, err)
+
+		p, err := tuna.LoadPayment(ctx, client, tuna.StatusRequest{PartnerUniqueID: "order-1"})
+		require.NoError(t, err)
+		assert.Equal(t, tuna.PaymentStatusAuthorized, p.Status())
+		assert.Equal(t, 100, p.Amount())
+		require.NoError(t, p.Capture(ctx, 40))
+		assert.Equal(t, 40, p.CapturedAmount())
+	})
+
+	t.Run("should capture the rest of a partially captured payment", func(t *testing.T) {
+		s, service := newService(t)
+
+		p, err := service.StartPayment(ctx, initRequest("order-1"))
+		require.NoError(t, err)
+		require.NoError(t, p.Capture(ctx, 60))
+		assert.Equal(t, tuna.PaymentStatusCaptured, p.Status())
+		assert.True(t, p.Can(tuna.EndpointCapture))
+
+		assert.ErrorIs(t, p.Capture(ctx, 50), tuna.ErrAmountExceeded)
+		require.NoError(t, p.Capture(ctx, 0))
+		assert.Equal(t, 100, p.CapturedAmount())
+
+		assert.False(t, p.Can(tuna.EndpointCapture))
+		assert.ErrorIs(t, p.Capture(ctx, 0), tuna.ErrIllegalOperation)
+		assert.Equal(t, 2, s.Calls(tuna.EndpointCapture))
+	})
+
+	t.Run("should not capture cancelled items", func(t *testing.T) {
+		s, service := newService(t)
+		request := initRequest("order-1")
+		request.PaymentItems = []tuna.PaymentItem{
+			{ProductDescription: "Book", Amount: 30, ItemQuantity: 2},
+			{ProductDescription: "Pen", Amount: 40, ItemQuantity: 1},
+		}
+
+		p, err := service.StartPayment(ctx, request)
+		require.NoError(t, err)
+
+		_, err = p.CancelItems(ctx, []tuna.ItemDetail{{DetailUniqueID: "0", ItemQuantity: 1}})
+		require.NoError(t, err)
+		assert.Equal(t, tuna.PaymentStatusAuthorized, p.Status())
+		assert.Equal(t, 30, p.CancelledAmount())
+
+		assert.ErrorIs(t, p.Capture(ctx, 80), tuna.ErrAmountExceeded)
+		assert.Equal(t, 0, s.Calls(tuna.EndpointCapture))
+		require.NoError(t, p.Capture(ctx, 0))
+		assert.Equal(t, 70, p.CapturedAmount())
+		assert.False(t, p.Can(tuna.EndpointCapture))
+	})
+
+	t.Run("should send the payment date given by Tuna", func(t *testing.T) {
+		date := time.Date(2024, 1, 2, 23, 30, 0, 0, time.UTC)
+		api := tunamock.NewPaymentAPI()
+		api.OnInit().Return(&tuna.InitResponse{Status: tuna.PaymentStatusAuthorized, PaymentDate: tuna.Date{Time: date}}, nil).Once()
+		api.OnCapture().
+			With(func(r tuna.CaptureRequest) bool { return r.PaymentDate.Equal(date) }).
+			Return(&tuna.CaptureResponse{Status: tuna.PaymentStatusCaptured}, nil).Once()
+		api.OnCancel().
+			With(func(r tuna.CancelRequest) bool { return r.PaymentDate == "2024-01-02" }).
+			Return(&tuna.CancelResponse{Status: tuna.PaymentStatusRefunded}, nil).Once()
+
+		p, err := tuna.StartPayment(ctx, api, initRequest("order-1"))
+		require.NoError(t, err)
+		require.NoError(t, p.Capture(ctx, 0))
+		require.NoError(t, p.Cancel(ctx))
+		assert.True(t, api.AssertExpectations(t))
+	})
+
+	t.Run("should load the payment date when Init does not return it", func(t *testing.T) {
+		date := time.Date(2024, 1, 2, 23, 30, 0, 0, time.UTC)
+		api := tunamock.NewPaymentAPI()
+		api.OnInit().Return(&tuna.InitResponse{Status: tuna.PaymentStatusAuthorized}, nil).Once()
+		api.OnStatus().Return(&tuna.StatusResponse{Status: tuna.PaymentStatusAuthorized, PaymentDate: tuna.Date{Time: date}}, nil).Once()
+		api.OnCancel().
+			With(func(r tuna.CancelRequest) bool { return r.PaymentDate == "2024-01-02" }).
+			Return(&tuna.CancelResponse{Status: tuna.PaymentStatusCancelled}, nil).Once()
+
+		p, err := tuna.StartPayment(ctx, api, initRequest("order-1"))
+		require.NoError(t, err)
+		require.NoError(t, p.Cancel(ctx))
+		assert.Equal(t, tuna.PaymentStatusCancelled, p.Status())
+		assert.True(t, api.AssertExpectations(t))
+	})
+}
@@ -0,0 +1,91 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const requestIDHeader = "Request-Id"
+
+type callInfoContextKey struct{}
+
+// callInfo collects what the transport learns about a call for the middlewares
+// that wrap it.
+type callInfo struct {
+	statusCode int
+	traceID    string
+}
+
+// withCallInfo returns the call info of ctx, adding one if there is none yet.
+func withCallInfo(ctx context.Context) (context.Context, *callInfo) {
+	if info, ok := ctx.Value(callInfoContextKey{}).(*callInfo); ok {
+		return ctx, info
+	}
+
+	info := &callInfo{}
+	return context.WithValue(ctx, callInfoContextKey{}, info), info
+}
+
+// recordResponse stores the status and trace ID of resp in the call info of
+// ctx, if any.
+func recordResponse(ctx context.Context, resp *http.Response) {
+	info, ok := ctx.Value(callInfoContextKey{}).(*callInfo)
+	if !ok {
+		return
+	}
+
+	info.statusCode = resp.StatusCode
+	if id := resp.Header.Get(requestIDHeader); id != "" {
+		info.traceID = id
+	}
+}
+
+// loggingMiddleware logs every call with its endpoint, duration, HTTP status
+// and trace ID. Requests and responses are redacted and only logged at debug
+// level.
+func loggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, endpoint Endpoint, request, response interface{}) error {
+			ctx, info := withCallInfo(ctx)
+			start := time.Now()
+
+			err := next(ctx, endpoint, request, response)
+
+			traceID := info.traceID
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.TraceID != "" {
+				traceID = apiErr.TraceID
+			}
+
+			attrs := []slog.Attr{
+				slog.String("endpoint", string(endpoint)),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("status", info.statusCode),
+			}
+			if traceID != "" {
+				attrs = append(attrs, slog.String("trace_id", traceID))
+			}
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				attrs = append(attrs, slog.Any("request", Redact(request)))
+				if err == nil {
+					attrs = append(attrs, slog.Any("response", Redact(response)))
+				}
+			}
+
+			level := slog.LevelInfo
+			msg := "tuna call succeeded"
+			if err != nil {
+				level = slog.LevelError
+				msg = "tuna call failed"
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+
+			logger.LogAttrs(ctx, level, msg, attrs...)
+
+			return err
+		}
+	}
+}
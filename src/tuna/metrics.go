@@ -0,0 +1,197 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Error kinds reported to Metrics.
+const (
+	ErrorKindHTTP        = "http"
+	ErrorKindBusiness    = "business"
+	ErrorKindCircuitOpen = "circuit_open"
+	ErrorKindCanceled    = "canceled"
+	ErrorKindTransport   = "transport"
+)
+
+// Payment outcomes reported to Metrics.
+const (
+	OutcomeApproved  = "approved"
+	OutcomeDeclined  = "declined"
+	OutcomePending   = "pending"
+	OutcomeCancelled = "cancelled"
+	OutcomeRefunded  = "refunded"
+	OutcomeUnknown   = "unknown"
+)
+
+var paymentStatusOutcomes = map[PaymentStatus]string{
+	PaymentStatusStarted:    OutcomePending,
+	PaymentStatusPending:    OutcomePending,
+	PaymentStatusAuthorized: OutcomeApproved,
+	PaymentStatusCaptured:   OutcomeApproved,
+	PaymentStatusDenied:     OutcomeDeclined,
+	PaymentStatusCancelled:  OutcomeCancelled,
+	PaymentStatusRefunded:   OutcomeRefunded,
+}
+
+// Metrics receives measurements of every call. Implementations must be safe
+// for concurrent use.
+type Metrics interface {
+	// ObserveRequest records a call and its latency. status is the HTTP status
+	// code of the last response, or "none" if no response was received.
+	ObserveRequest(endpoint Endpoint, status string, duration time.Duration)
+	// IncError counts a failed call by the kind of its error.
+	IncError(endpoint Endpoint, kind string)
+	// IncPaymentOutcome counts the status of the payment reported in a
+	// successful response, as one of the Outcome constants. The statuses of
+	// its payment methods are not counted, so each response counts once.
+	IncPaymentOutcome(endpoint Endpoint, outcome string)
+}
+
+// metricsMiddleware reports every call to m.
+func metricsMiddleware(m Metrics) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, endpoint Endpoint, request, response interface{}) error {
+			ctx, info := withCallInfo(ctx)
+			start := time.Now()
+
+			err := next(ctx, endpoint, request, response)
+
+			status := "none"
+			if info.statusCode != 0 {
+				status = strconv.Itoa(info.statusCode)
+			}
+			m.ObserveRequest(endpoint, status, time.Since(start))
+
+			if err != nil {
+				m.IncError(endpoint, errorKind(err))
+				return err
+			}
+
+			if status := paymentStatus(response); status != "" {
+				m.IncPaymentOutcome(endpoint, paymentOutcome(status))
+			}
+
+			return nil
+		}
+	}
+}
+
+func errorKind(err error) string {
+	var apiErr *APIError
+	var businessErr *BusinessError
+
+	switch {
+	case errors.As(err, &apiErr):
+		return ErrorKindHTTP
+	case errors.As(err, &businessErr):
+		return ErrorKindBusiness
+	case errors.Is(err, ErrCircuitOpen):
+		return ErrorKindCircuitOpen
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindCanceled
+	default:
+		return ErrorKindTransport
+	}
+}
+
+// paymentStatus returns the payment status reported in response, if any.
+func paymentStatus(response interface{}) PaymentStatus {
+	switch r := response.(type) {
+	case *InitResponse:
+		return r.Status
+	case *CancelResponse:
+		return r.Status
+	case *CaptureResponse:
+		return r.Status
+	}
+	return ""
+}
+
+// paymentOutcome maps status to a label of bounded cardinality.
+func paymentOutcome(status PaymentStatus) string {
+	if outcome, ok := paymentStatusOutcomes[status]; ok {
+		return outcome
+	}
+	return OutcomeUnknown
+}
+
+// MemoryMetrics is a Metrics implementation that keeps every measurement in
+// memory, meant for tests.
+type MemoryMetrics struct {
+	mu        sync.Mutex
+	requests  map[string]int
+	durations map[Endpoint][]time.Duration
+	errors    map[string]int
+	outcomes  map[string]int
+}
+
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		requests:  make(map[string]int),
+		durations: make(map[Endpoint][]time.Duration),
+		errors:    make(map[string]int),
+		outcomes:  make(map[string]int),
+	}
+}
+
+func metricKey(endpoint Endpoint, label string) string {
+	return string(endpoint) + "|" + label
+}
+
+func (m *MemoryMetrics) ObserveRequest(endpoint Endpoint, status string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[metricKey(endpoint, status)]++
+	m.durations[endpoint] = append(m.durations[endpoint], duration)
+}
+
+func (m *MemoryMetrics) IncError(endpoint Endpoint, kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.errors[metricKey(endpoint, kind)]++
+}
+
+func (m *MemoryMetrics) IncPaymentOutcome(endpoint Endpoint, outcome string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outcomes[metricKey(endpoint, outcome)]++
+}
+
+// Requests returns the number of calls to endpoint that ended with status.
+func (m *MemoryMetrics) Requests(endpoint Endpoint, status string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.requests[metricKey(endpoint, status)]
+}
+
+// Durations returns the latencies recorded for endpoint.
+func (m *MemoryMetrics) Durations(endpoint Endpoint) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]time.Duration(nil), m.durations[endpoint]...)
+}
+
+// Errors returns the number of calls to endpoint that failed with kind.
+func (m *MemoryMetrics) Errors(endpoint Endpoint, kind string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.errors[metricKey(endpoint, kind)]
+}
+
+// PaymentOutcomes returns the number of times endpoint reported outcome.
+func (m *MemoryMetrics) PaymentOutcomes(endpoint Endpoint, outcome string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.outcomes[metricKey(endpoint, outcome)]
+}
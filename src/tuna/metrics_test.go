@@ -0,0 +1,61 @@
+package tuna
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("should record requests and payment outcomes", func(t *testing.T) {
+		statuses := []string{"1", "4", "X"}
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			status := statuses[0]
+			statuses = statuses[1:]
+			return &http.Response{
+				StatusCode: 200,
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{
+					"status": "` + status + `",
+					"methods": [{"status": "1"}, {"status": "6"}]
+				}`)),
+				Header: make(http.Header),
+			}
+		})
+		metrics := NewMemoryMetrics()
+		api := newTestPaymentClient(t, client, Config{Metrics: metrics})
+
+		for i := 0; i < 3; i++ {
+			_, err := api.Init(InitRequest{})
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, 3, metrics.Requests(EndpointInit, "200"))
+		assert.Len(t, metrics.Durations(EndpointInit), 3)
+		assert.Equal(t, 1, metrics.PaymentOutcomes(EndpointInit, OutcomeApproved))
+		assert.Equal(t, 1, metrics.PaymentOutcomes(EndpointInit, OutcomeDeclined))
+		assert.Equal(t, 0, metrics.PaymentOutcomes(EndpointInit, OutcomePending))
+		assert.Equal(t, 1, metrics.PaymentOutcomes(EndpointInit, OutcomeUnknown))
+		assert.Equal(t, 0, metrics.PaymentOutcomes(EndpointInit, "1"))
+	})
+
+	t.Run("should count errors by kind", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		metrics := NewMemoryMetrics()
+		api := newTestTokenClient(t, client, Config{Metrics: metrics})
+
+		_, err := api.BindCVV(BindCVVRequest{})
+		assert.Error(t, err)
+
+		assert.Equal(t, 1, metrics.Requests(EndpointBindCVV, "400"))
+		assert.Equal(t, 1, metrics.Errors(EndpointBindCVV, ErrorKindHTTP))
+	})
+}
@@ -0,0 +1,23 @@
+package tuna
+
+import "context"
+
+// Endpoint names a Tuna API call, e.g. "Payment.Init".
+type Endpoint string
+
+// Invoker performs a call to endpoint. request is the typed request value, e.g.
+// InitRequest, and response a pointer to the typed response, e.g. *InitResponse,
+// which is filled in once the call succeeds.
+type Invoker func(ctx context.Context, endpoint Endpoint, request, response interface{}) error
+
+// Middleware wraps an Invoker. It may inspect or replace the request before
+// calling next, inspect the response after, or not call next at all.
+type Middleware func(next Invoker) Invoker
+
+// chain wraps invoker in middlewares, the first one being the outermost.
+func chain(middlewares []Middleware, invoker Invoker) Invoker {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		invoker = middlewares[i](invoker)
+	}
+	return invoker
+}
@@ -0,0 +1,90 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Run("should run middlewares in order around the call", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"paymentKey": "key"}`)),
+				Header:     make(http.Header),
+			}
+		})
+
+		var trace []string
+		record := func(name string) Middleware {
+			return func(next Invoker) Invoker {
+				return func(ctx context.Context, endpoint Endpoint, request, response interface{}) error {
+					trace = append(trace, name+" before "+string(endpoint))
+					err := next(ctx, endpoint, request, response)
+					trace = append(trace, name+" after "+response.(*InitResponse).PaymentKey)
+					return err
+				}
+			}
+		}
+		api := newTestPaymentClient(t, client, Config{Middlewares: []Middleware{record("outer"), record("inner")}})
+
+		_, err := api.Init(InitRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"outer before Payment.Init",
+			"inner before Payment.Init",
+			"inner after key",
+			"outer after key",
+		}, trace)
+	})
+
+	t.Run("should let a middleware replace the request", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			body, _ := ioutil.ReadAll(req.Body)
+			assert.JSONEq(t, `{"sessionId": "replaced"}`, string(body))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		replace := func(next Invoker) Invoker {
+			return func(ctx context.Context, endpoint Endpoint, request, response interface{}) error {
+				r := request.(ListTokensRequest)
+				r.SessionID = "replaced"
+				return next(ctx, endpoint, r, response)
+			}
+		}
+		api := newTestTokenClient(t, client, Config{Middlewares: []Middleware{replace}})
+
+		_, err := api.ListTokens(ListTokensRequest{SessionID: "original"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should let a middleware short-circuit the call", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			t.Fatal("request should not be sent")
+			return nil
+		})
+		injected := errors.New("injected fault")
+		fault := func(next Invoker) Invoker {
+			return func(ctx context.Context, endpoint Endpoint, request, response interface{}) error {
+				if endpoint == EndpointCapture {
+					return injected
+				}
+				return next(ctx, endpoint, request, response)
+			}
+		}
+		api := newTestPaymentClient(t, client, Config{Middlewares: []Middleware{fault}})
+
+		res, err := api.Capture(CaptureRequest{})
+		assert.ErrorIs(t, err, injected)
+		assert.Nil(t, res)
+	})
+}
@@ -1,11 +1,8 @@
 package tuna
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
-	"net/url"
 	"time"
 )
 
@@ -20,289 +17,173 @@ const (
 	functionPath    = "/api/Payment/Function"
 )
 
+const (
+	EndpointInit       Endpoint = "Payment.Init"
+	EndpointCancel     Endpoint = "Payment.Cancel"
+	EndpointCancelItem Endpoint = "Payment.CancelItem"
+	EndpointCapture    Endpoint = "Payment.Capture"
+	EndpointContinue   Endpoint = "Payment.Continue"
+	EndpointStatus     Endpoint = "Payment.Status"
+	EndpointOptions    Endpoint = "Payment.Options"
+	EndpointFunction   Endpoint = "Payment.Function"
+)
+
+var (
+	initEndpoint       = endpoint{name: EndpointInit, method: http.MethodPost, path: initPaymentPath}
+	cancelEndpoint     = endpoint{name: EndpointCancel, method: http.MethodPost, path: cancelPath}
+	cancelItemEndpoint = endpoint{name: EndpointCancelItem, method: http.MethodPost, path: cancelItemPath}
+	captureEndpoint    = endpoint{name: EndpointCapture, method: http.MethodPost, path: capturePath}
+	continueEndpoint   = endpoint{name: EndpointContinue, method: http.MethodPost, path: continuePath}
+	statusEndpoint     = endpoint{name: EndpointStatus, method: http.MethodPost, path: statusPath, idempotent: true}
+	optionsEndpoint    = endpoint{name: EndpointOptions, method: http.MethodPost, path: optionsPath, idempotent: true}
+	functionEndpoint   = endpoint{name: EndpointFunction, method: http.MethodPost, path: functionPath}
+)
+
 type PaymentAPI interface {
 	Init(request InitRequest) (*InitResponse, error)
+	InitWithContext(ctx context.Context, request InitRequest) (*InitResponse, error)
 	Cancel(request CancelRequest) (*CancelResponse, error)
+	CancelWithContext(ctx context.Context, request CancelRequest) (*CancelResponse, error)
 	CancelItem(request CancelItemRequest) (*CancelItemResponse, error)
+	CancelItemWithContext(ctx context.Context, request CancelItemRequest) (*CancelItemResponse, error)
 	Capture(request CaptureRequest) (*CaptureResponse, error)
+	CaptureWithContext(ctx context.Context, request CaptureRequest) (*CaptureResponse, error)
 	Continue(request ContinueRequest) (*ContinueResponse, error)
+	ContinueWithContext(ctx context.Context, request ContinueRequest) (*ContinueResponse, error)
 	Status(request StatusRequest) (*StatusResponse, error)
+	StatusWithContext(ctx context.Context, request StatusRequest) (*StatusResponse, error)
 	Options(request OptionsRequest) (*OptionsResponse, error)
+	OptionsWithContext(ctx context.Context, request OptionsRequest) (*OptionsResponse, error)
 	Function(request FunctionRequest) (*FunctionResponse, error)
+	FunctionWithContext(ctx context.Context, request FunctionRequest) (*FunctionResponse, error)
 }
 
 type PaymentClient struct {
-	baseURL    *url.URL
-	httpClient *http.Client
-	userAgent  string
-	appToken   string
-}
-
-func NewPaymentClient(client *http.Client, conf Config) *PaymentClient {
-	parsedURL, _ := url.Parse(conf.BaseURL)
-
-	return &PaymentClient{
-		httpClient: client,
-		baseURL:    parsedURL,
-		userAgent:  conf.UserAgent,
-		appToken:   conf.AppToken,
-	}
+	transport *transport
 }
 
-func (p PaymentClient) Init(request InitRequest) (*InitResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: initPaymentPath}
-	u := p.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
+// NewPaymentClient returns a client for the base URL of conf. It fails if conf has
+// no app token or no valid base URL.
+func NewPaymentClient(client *http.Client, conf Config) (*PaymentClient, error) {
+	baseURL, err := conf.paymentBaseURL()
 	if err != nil {
 		return nil, err
 	}
-
-	setHeaders(req, p.userAgent, p.appToken)
-
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+	if err := conf.validateCredentials(); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
+	return &PaymentClient{transport: newTransport(client, conf, baseURL)}, nil
+}
 
-	var ir InitResponse
-	err = json.NewDecoder(resp.Body).Decode(&ir)
+// CircuitState reports the state of the circuit breaker. It is always
+// CircuitClosed when no circuit breaker is configured.
+func (p PaymentClient) CircuitState() CircuitState {
+	return p.transport.breaker.State()
+}
 
-	return &ir, err
+func (p PaymentClient) Init(request InitRequest) (*InitResponse, error) {
+	return p.InitWithContext(context.Background(), request)
 }
 
-func (p PaymentClient) Cancel(request CancelRequest) (*CancelResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
+func (p PaymentClient) InitWithContext(ctx context.Context, request InitRequest) (*InitResponse, error) {
+	var ir InitResponse
+	if err := p.transport.do(ctx, initEndpoint, request, &ir); err != nil {
 		return nil, err
 	}
-	rel := &url.URL{Path: cancelPath}
-	u := p.baseURL.ResolveReference(rel)
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
+	return &ir, nil
+}
 
-	setHeaders(req, p.userAgent, p.appToken)
+func (p PaymentClient) Cancel(request CancelRequest) (*CancelResponse, error) {
+	return p.CancelWithContext(context.Background(), request)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+func (p PaymentClient) CancelWithContext(ctx context.Context, request CancelRequest) (*CancelResponse, error) {
+	var cr CancelResponse
+	if err := p.transport.do(ctx, cancelEndpoint, request, &cr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var cr CancelResponse
-	err = json.NewDecoder(resp.Body).Decode(&cr)
-
-	return &cr, err
+	return &cr, nil
 }
 
 func (p PaymentClient) CancelItem(request CancelItemRequest) (*CancelItemResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: cancelItemPath}
-	u := p.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, p.userAgent, p.appToken)
+	return p.CancelItemWithContext(context.Background(), request)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+func (p PaymentClient) CancelItemWithContext(ctx context.Context, request CancelItemRequest) (*CancelItemResponse, error) {
+	var cir CancelItemResponse
+	if err := p.transport.do(ctx, cancelItemEndpoint, request, &cir); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var cir CancelItemResponse
-	err = json.NewDecoder(resp.Body).Decode(&cir)
 
-	return &cir, err
+	return &cir, nil
 }
 
 func (p PaymentClient) Capture(request CaptureRequest) (*CaptureResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: capturePath}
-	u := p.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, p.userAgent, p.appToken)
+	return p.CaptureWithContext(context.Background(), request)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+func (p PaymentClient) CaptureWithContext(ctx context.Context, request CaptureRequest) (*CaptureResponse, error) {
+	var cr CaptureResponse
+	if err := p.transport.do(ctx, captureEndpoint, request, &cr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
 
-	var cr CaptureResponse
-	err = json.NewDecoder(resp.Body).Decode(&cr)
-
-	return &cr, err
+	return &cr, nil
 }
 
 func (p PaymentClient) Continue(request ContinueRequest) (*ContinueResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: continuePath}
-	u := p.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, p.userAgent, p.appToken)
+	return p.ContinueWithContext(context.Background(), request)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+func (p PaymentClient) ContinueWithContext(ctx context.Context, request ContinueRequest) (*ContinueResponse, error) {
+	var cr ContinueResponse
+	if err := p.transport.do(ctx, continueEndpoint, request, &cr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var cr ContinueResponse
-	err = json.NewDecoder(resp.Body).Decode(&cr)
 
-	return &cr, err
+	return &cr, nil
 }
 
 func (p PaymentClient) Status(request StatusRequest) (*StatusResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: statusPath}
-	u := p.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, p.userAgent, p.appToken)
+	return p.StatusWithContext(context.Background(), request)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+func (p PaymentClient) StatusWithContext(ctx context.Context, request StatusRequest) (*StatusResponse, error) {
+	var sr StatusResponse
+	if err := p.transport.do(ctx, statusEndpoint, request, &sr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var sr StatusResponse
-	err = json.NewDecoder(resp.Body).Decode(&sr)
 
-	return &sr, err
+	return &sr, nil
 }
 
 func (p PaymentClient) Options(request OptionsRequest) (*OptionsResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: optionsPath}
-	u := p.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, p.userAgent, p.appToken)
+	return p.OptionsWithContext(context.Background(), request)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+func (p PaymentClient) OptionsWithContext(ctx context.Context, request OptionsRequest) (*OptionsResponse, error) {
+	var or OptionsResponse
+	if err := p.transport.do(ctx, optionsEndpoint, request, &or); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
 
-	var or OptionsResponse
-	err = json.NewDecoder(resp.Body).Decode(&or)
-
-	return &or, err
+	return &or, nil
 }
 
 func (p PaymentClient) Function(request FunctionRequest) (*FunctionResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: functionPath}
-	u := p.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, p.userAgent, p.appToken)
+	return p.FunctionWithContext(context.Background(), request)
+}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
+func (p PaymentClient) FunctionWithContext(ctx context.Context, request FunctionRequest) (*FunctionResponse, error) {
+	var fr FunctionResponse
+	if err := p.transport.do(ctx, functionEndpoint, request, &fr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var fr FunctionResponse
-	err = json.NewDecoder(resp.Body).Decode(&fr)
 
-	return &fr, err
+	return &fr, nil
 }
 
 type InitRequest struct {
@@ -314,12 +195,13 @@ type InitRequest struct {
 }
 
 type InitResponse struct {
-	Status          string       `json:"status"`
-	Methods         []Method     `json:"methods"`
-	PaymentKey      string       `json:"paymentKey"`
-	PartnerUniqueId string       `json:"partnerUniqueId"`
-	Message         Message      `json:"message"`
-	RedirectInfo    RedirectInfo `json:"redirectInfo"`
+	Status          PaymentStatus `json:"status"`
+	Methods         []Method      `json:"methods"`
+	PaymentKey      string        `json:"paymentKey"`
+	PartnerUniqueId string        `json:"partnerUniqueId"`
+	PaymentDate     Date          `json:"paymentDate"`
+	Message         Message       `json:"message"`
+	RedirectInfo    RedirectInfo  `json:"redirectInfo"`
 }
 
 type CancelRequest struct {
@@ -330,9 +212,9 @@ type CancelRequest struct {
 }
 
 type CancelResponse struct {
-	Status  string   `json:"status"`
-	Methods []Method `json:"methods"`
-	Message Message  `json:"message"`
+	Status  PaymentStatus `json:"status"`
+	Methods []Method      `json:"methods"`
+	Message Message       `json:"message"`
 }
 
 type CancelItemRequest struct {
@@ -342,9 +224,9 @@ type CancelItemRequest struct {
 }
 
 type CancelItemResponse struct {
-	Status  string  `json:"status"`
-	Items   []Item  `json:"Items"`
-	Message Message `json:"message"`
+	Status  PaymentStatus `json:"status"`
+	Items   []Item        `json:"Items"`
+	Message Message       `json:"message"`
 }
 
 type CaptureRequest struct {
@@ -353,15 +235,15 @@ type CaptureRequest struct {
 	PaymentKey      string       `json:"paymentKey"`
 	PartnerUniqueID string       `json:"partnerUniqueID"`
 	PaymentDate     time.Time    `json:"paymentDate"`
-	AppToken        string       `json:"appToken"`
+	AppToken        string       `json:"appToken" redact:"remove"`
 	Account         string       `json:"account"`
 	ExtraInfo       string       `json:"extraInfo"`
 }
 
 type CaptureResponse struct {
-	Status  string   `json:"status"`
-	Methods []Method `json:"methods"`
-	Message Message  `json:"message"`
+	Status  PaymentStatus `json:"status"`
+	Methods []Method      `json:"methods"`
+	Message Message       `json:"message"`
 }
 
 type ContinueRequest struct {
@@ -370,7 +252,7 @@ type ContinueRequest struct {
 	PaymentKey      string         `json:"paymentKey"`
 	PartnerUniqueID string         `json:"partnerUniqueID"`
 	PaymentDate     time.Time      `json:"paymentDate"`
-	AppToken        string         `json:"appToken"`
+	AppToken        string         `json:"appToken" redact:"remove"`
 	Account         string         `json:"account"`
 	ExtraInfo       string         `json:"extraInfo"`
 }
@@ -384,16 +266,14 @@ type StatusRequest struct {
 	PaymentDate     time.Time `json:"paymentDate"`
 	PaymentKey      string    `json:"paymentKey"`
 	PartnerID       int       `json:"partnerID"`
-	AppToken        string    `json:"appToken"`
+	AppToken        string    `json:"appToken" redact:"remove"`
 	Account         string    `json:"account"`
 	ExtraInfo       string    `json:"extraInfo"`
 }
 
-type StatusResponse struct{}
-
 type OptionsRequest struct {
 	PartnerID int    `json:"partnerID"`
-	AppToken  string `json:"appToken"`
+	AppToken  string `json:"appToken" redact:"remove"`
 	Account   string `json:"account"`
 	ExtraInfo string `json:"extraInfo"`
 }
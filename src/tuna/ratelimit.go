@@ -0,0 +1,171 @@
+package tuna
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit throttles calls on the client side. Calls over the limit block
+// until they are allowed or their context is done.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate of the token bucket. Zero means
+	// no rate limit.
+	RequestsPerSecond float64
+	// Burst is the bucket size. Defaults to 1.
+	Burst int
+	// MaxInFlight caps the number of concurrent calls. Zero means no cap.
+	MaxInFlight int
+}
+
+// limiter enforces a RateLimit. It is safe for concurrent use.
+type limiter struct {
+	bucket   *tokenBucket
+	inFlight chan struct{}
+}
+
+func newLimiter(rl RateLimit) *limiter {
+	l := &limiter{}
+	if rl.RequestsPerSecond > 0 {
+		l.bucket = newTokenBucket(rl.RequestsPerSecond, rl.Burst)
+	}
+	if rl.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, rl.MaxInFlight)
+	}
+	return l
+}
+
+// acquire blocks until a call is allowed and returns the function that must be
+// called once it is over. It waits on the bucket before taking an in-flight
+// slot, so calls throttled by the rate do not hold slots while asleep.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l.bucket != nil {
+		if err := l.bucket.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return func() { <-l.inFlight }, nil
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// limiters holds the client-wide limiter and the ones of endpoint groups.
+type limiters struct {
+	client    *limiter
+	endpoints map[string]*limiter
+}
+
+func newLimiters(client *RateLimit, endpoints map[string]RateLimit) *limiters {
+	if client == nil && len(endpoints) == 0 {
+		return nil
+	}
+
+	ls := &limiters{endpoints: make(map[string]*limiter, len(endpoints))}
+	if client != nil {
+		ls.client = newLimiter(*client)
+	}
+	for group, rl := range endpoints {
+		ls.endpoints[group] = newLimiter(rl)
+	}
+	return ls
+}
+
+// endpointLimiter returns the limiter of the most specific group e belongs to:
+// its own name, e.g. "Payment.Status", or its API, e.g. "Payment".
+func (ls *limiters) endpointLimiter(e endpoint) *limiter {
+	if l, ok := ls.endpoints[string(e.name)]; ok {
+		return l
+	}
+
+	api := strings.SplitN(string(e.name), ".", 2)[0]
+	return ls.endpoints[api]
+}
+
+// acquire goes through the limiter of the endpoint group first, then the
+// client-wide one, so calls held back by their group do not take client-wide
+// slots away from other endpoints.
+func (ls *limiters) acquire(ctx context.Context, e endpoint) (func(), error) {
+	noop := func() {}
+	if ls == nil {
+		return noop, nil
+	}
+
+	releaseEndpoint := noop
+	if l := ls.endpointLimiter(e); l != nil {
+		release, err := l.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		releaseEndpoint = release
+	}
+
+	if ls.client == nil {
+		return releaseEndpoint, nil
+	}
+
+	releaseClient, err := ls.client.acquire(ctx)
+	if err != nil {
+		releaseEndpoint()
+		return nil, err
+	}
+
+	return func() {
+		releaseClient()
+		releaseEndpoint()
+	}, nil
+}
@@ -0,0 +1,118 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("should cap the number of calls in flight", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{
+			EndpointRateLimits: map[string]RateLimit{"Payment.Status": {MaxInFlight: 2}},
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := api.Status(StatusRequest{})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), maxInFlight)
+	})
+
+	t.Run("should block until the context is done", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestTokenClient(t, client, Config{
+			RateLimit: &RateLimit{RequestsPerSecond: 0.01, Burst: 1},
+		})
+
+		_, err := api.ListTokens(ListTokensRequest{})
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = api.ListTokensWithContext(ctx, ListTokensRequest{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should not let a throttled group block other endpoints", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{
+			RateLimit:          &RateLimit{MaxInFlight: 2},
+			EndpointRateLimits: map[string]RateLimit{"Payment.Status": {RequestsPerSecond: 0.01, MaxInFlight: 1}},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				api.StatusWithContext(ctx, StatusRequest{})
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		initCtx, initCancel := context.WithTimeout(context.Background(), time.Second)
+		defer initCancel()
+		_, err := api.InitWithContext(initCtx, InitRequest{})
+		assert.NoError(t, err)
+
+		cancel()
+		wg.Wait()
+	})
+
+	t.Run("should pick the most specific endpoint group", func(t *testing.T) {
+		ls := newLimiters(nil, map[string]RateLimit{
+			"Payment":        {MaxInFlight: 1},
+			"Payment.Status": {MaxInFlight: 2},
+		})
+
+		assert.Equal(t, ls.endpoints["Payment.Status"], ls.endpointLimiter(statusEndpoint))
+		assert.Equal(t, ls.endpoints["Payment"], ls.endpointLimiter(initEndpoint))
+		assert.Nil(t, ls.endpointLimiter(listTokensEndpoint))
+	})
+}
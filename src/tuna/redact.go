@@ -0,0 +1,403 @@
+package tuna
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fields are redacted according to their `redact` struct tag:
+//
+//	pan     keeps the first 6 and last 4 digits of a card number
+//	mask    keeps the last 4 characters, e.g. of a card token or document
+//	email   keeps the first character of the local part and the domain
+//	remove  drops the field altogether, e.g. a CVV or an app token
+const redactTag = "redact"
+
+const (
+	redactPAN    = "pan"
+	redactMask   = "mask"
+	redactEmail  = "email"
+	redactRemove = "remove"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Redact returns a copy of v safe to log: structs become maps keyed by their
+// JSON field names, and sensitive fields are masked or removed.
+func Redact(v interface{}) interface{} {
+	return redactValue(reflect.ValueOf(v), "")
+}
+
+func redactValue(v reflect.Value, rule string) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), rule)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface()
+		}
+		fields := make(map[string]interface{}, v.NumField())
+		eachField(v, func(f reflect.StructField, fv reflect.Value, fieldRule string) {
+			fields[jsonName(f)] = redactValue(fv, fieldRule)
+		})
+		return fields
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = redactValue(v.Index(i), rule)
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value(), rule)
+		}
+		return entries
+	}
+
+	if rule != "" {
+		return applyRedaction(fmt.Sprint(v.Interface()), rule)
+	}
+	return v.Interface()
+}
+
+// eachField calls fn with every exported field of the struct v that is not
+// removed by its redaction rule.
+func eachField(v reflect.Value, fn func(f reflect.StructField, fv reflect.Value, rule string)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		rule := f.Tag.Get(redactTag)
+		if rule == redactRemove {
+			continue
+		}
+		fn(f, v.Field(i), rule)
+	}
+}
+
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+func applyRedaction(s, rule string) string {
+	if s == "" {
+		return s
+	}
+
+	switch rule {
+	case redactPAN:
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, s)
+		if len(digits) < 13 {
+			return strings.Repeat("*", len(s))
+		}
+		return digits[:6] + strings.Repeat("*", len(digits)-10) + digits[len(digits)-4:]
+	case redactEmail:
+		at := strings.LastIndex(s, "@")
+		if at < 1 {
+			return strings.Repeat("*", len(s))
+		}
+		return s[:1] + "***" + s[at:]
+	default:
+		if len(s) <= 8 {
+			return strings.Repeat("*", len(s))
+		}
+		return strings.Repeat("*", len(s)-4) + s[len(s)-4:]
+	}
+}
+
+// formatRedacted formats v like %+v does, with sensitive fields redacted.
+func formatRedacted(v interface{}) string {
+	var b strings.Builder
+	writeRedacted(&b, reflect.ValueOf(v), "")
+	return b.String()
+}
+
+func writeRedacted(b *strings.Builder, v reflect.Value, rule string) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("<nil>")
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("<nil>")
+			return
+		}
+		writeRedacted(b, v.Elem(), rule)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			fmt.Fprint(b, v.Interface())
+			return
+		}
+		b.WriteString("{")
+		first := true
+		eachField(v, func(f reflect.StructField, fv reflect.Value, fieldRule string) {
+			if !first {
+				b.WriteString(" ")
+			}
+			first = false
+			b.WriteString(f.Name + ":")
+			writeRedacted(b, fv, fieldRule)
+		})
+		b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			writeRedacted(b, v.Index(i), rule)
+		}
+		b.WriteString("]")
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := fmt.Sprint(iter.Key().Interface())
+			keys = append(keys, k)
+			values[k] = iter.Value()
+		}
+		sort.Strings(keys)
+		b.WriteString("map[")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(k + ":")
+			writeRedacted(b, values[k], rule)
+		}
+		b.WriteString("]")
+	default:
+		if rule != "" {
+			b.WriteString(applyRedaction(fmt.Sprint(v.Interface()), rule))
+			return
+		}
+		fmt.Fprint(b, v.Interface())
+	}
+}
+
+func (c Customer) String() string {
+	return formatRedacted(c)
+}
+
+func (c Customer) GoString() string {
+	return "tuna.Customer" + formatRedacted(c)
+}
+
+func (c Customer) LogValue() slog.Value {
+	return slog.AnyValue(Redact(c))
+}
+
+func (t TokenData) String() string {
+	return formatRedacted(t)
+}
+
+func (t TokenData) GoString() string {
+	return "tuna.TokenData" + formatRedacted(t)
+}
+
+func (t TokenData) LogValue() slog.Value {
+	return slog.AnyValue(Redact(t))
+}
+
+func (b BillingInfo) String() string {
+	return formatRedacted(b)
+}
+
+func (b BillingInfo) GoString() string {
+	return "tuna.BillingInfo" + formatRedacted(b)
+}
+
+func (b BillingInfo) LogValue() slog.Value {
+	return slog.AnyValue(Redact(b))
+}
+
+func (c CardInfo) String() string {
+	return formatRedacted(c)
+}
+
+func (c CardInfo) GoString() string {
+	return "tuna.CardInfo" + formatRedacted(c)
+}
+
+func (c CardInfo) LogValue() slog.Value {
+	return slog.AnyValue(Redact(c))
+}
+
+func (g GiftCard) String() string {
+	return formatRedacted(g)
+}
+
+func (g GiftCard) GoString() string {
+	return "tuna.GiftCard" + formatRedacted(g)
+}
+
+func (g GiftCard) LogValue() slog.Value {
+	return slog.AnyValue(Redact(g))
+}
+
+func (r BindCVVRequest) String() string {
+	return formatRedacted(r)
+}
+
+func (r BindCVVRequest) GoString() string {
+	return "tuna.BindCVVRequest" + formatRedacted(r)
+}
+
+func (r BindCVVRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (d CardDetail) String() string {
+	return formatRedacted(d)
+}
+
+func (d CardDetail) GoString() string {
+	return "tuna.CardDetail" + formatRedacted(d)
+}
+
+func (d CardDetail) LogValue() slog.Value {
+	return slog.AnyValue(Redact(d))
+}
+
+func (r GenerateCardTokenResponse) String() string {
+	return formatRedacted(r)
+}
+
+func (r GenerateCardTokenResponse) GoString() string {
+	return "tuna.GenerateCardTokenResponse" + formatRedacted(r)
+}
+
+func (r GenerateCardTokenResponse) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r DeleteCardTokenRequest) String() string {
+	return formatRedacted(r)
+}
+
+func (r DeleteCardTokenRequest) GoString() string {
+	return "tuna.DeleteCardTokenRequest" + formatRedacted(r)
+}
+
+func (r DeleteCardTokenRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r CaptureRequest) String() string {
+	return formatRedacted(r)
+}
+
+func (r CaptureRequest) GoString() string {
+	return "tuna.CaptureRequest" + formatRedacted(r)
+}
+
+func (r CaptureRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r ContinueRequest) String() string {
+	return formatRedacted(r)
+}
+
+func (r ContinueRequest) GoString() string {
+	return "tuna.ContinueRequest" + formatRedacted(r)
+}
+
+func (r ContinueRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r StatusRequest) String() string {
+	return formatRedacted(r)
+}
+
+func (r StatusRequest) GoString() string {
+	return "tuna.StatusRequest" + formatRedacted(r)
+}
+
+func (r StatusRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r OptionsRequest) String() string {
+	return formatRedacted(r)
+}
+
+func (r OptionsRequest) GoString() string {
+	return "tuna.OptionsRequest" + formatRedacted(r)
+}
+
+func (r OptionsRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+// slog only resolves LogValuer on the value it is given and JSON-encodes
+// anything nested, so the types below, which hold the ones above, redact
+// their whole tree when logged.
+func (r InitRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (d PaymentData) LogValue() slog.Value {
+	return slog.AnyValue(Redact(d))
+}
+
+func (m PaymentMethods) LogValue() slog.Value {
+	return slog.AnyValue(Redact(m))
+}
+
+func (r NewSessionRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r ValidadeSessionResponse) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r GenerateCardTokenRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r ListTokensResponse) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r CancelRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (r FunctionRequest) LogValue() slog.Value {
+	return slog.AnyValue(Redact(r))
+}
+
+func (a Arguments) LogValue() slog.Value {
+	return slog.AnyValue(Redact(a))
+}
@@ -0,0 +1,147 @@
+package tuna
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	t.Run("should mask card numbers, tokens, emails and documents", func(t *testing.T) {
+		req := InitRequest{
+			Customer: Customer{ID: "1", Email: "john@example.com"},
+			PaymentData: PaymentData{PaymentMethods: []PaymentMethods{{
+				CardInfo: CardInfo{
+					CardNumber:  "4111 1111 1111 1111",
+					Token:       "tok_1234567890",
+					BillingInfo: BillingInfo{Document: "12345678901"},
+				},
+			}}},
+		}
+
+		redacted := Redact(req).(map[string]interface{})
+		customer := redacted["Customer"].(map[string]interface{})
+		method := redacted["PaymentData"].(map[string]interface{})["PaymentMethods"].([]interface{})[0].(map[string]interface{})
+		card := method["CardInfo"].(map[string]interface{})
+
+		assert.Equal(t, "j***@example.com", customer["email"])
+		assert.Equal(t, "411111******1111", card["CardNumber"])
+		assert.Equal(t, "**********7890", card["Token"])
+		assert.Equal(t, "*******8901", card["BillingInfo"].(map[string]interface{})["Document"])
+	})
+
+	t.Run("should remove CVV and app tokens", func(t *testing.T) {
+		bind := Redact(BindCVVRequest{Token: "tok_1234567890", CVV: "123"}).(map[string]interface{})
+		assert.NotContains(t, bind, "CVV")
+
+		capture := Redact(CaptureRequest{AppToken: "secret"}).(map[string]interface{})
+		assert.NotContains(t, capture, "appToken")
+	})
+
+	t.Run("should keep formatting with fmt safe", func(t *testing.T) {
+		card := CardInfo{CardNumber: "4111111111111111", CardHolderName: "John"}
+		req := BindCVVRequest{Token: "tok_1234567890", CVV: "123"}
+		items := []TokenData{{Token: "tok_1234567890"}}
+
+		for _, s := range []string{
+			fmt.Sprintf("%v", card),
+			fmt.Sprintf("%+v", PaymentMethods{CardInfo: card}),
+			fmt.Sprintf("%#v", card),
+			fmt.Sprintf("%v", req),
+			fmt.Sprintf("%#v", &req),
+			fmt.Sprintf("%v", items),
+		} {
+			assert.NotContains(t, s, "4111111111111111")
+			assert.NotContains(t, s, "tok_1234567890")
+			assert.NotContains(t, s, "123}")
+		}
+		assert.Contains(t, fmt.Sprintf("%v", card), "CardNumber:411111******1111")
+	})
+
+	t.Run("should redact card details, tokens and app tokens in requests", func(t *testing.T) {
+		detail := CardDetail{MethodId: 1, Amount: 100}
+		detail.Data.CardNumber = "4111111111111111"
+		values := []interface{}{
+			CaptureRequest{CardsDetail: []CardDetail{detail}, AppToken: "secret-app-token"},
+			CancelRequest{CardsDetail: []CardDetail{detail}},
+			&CancelRequest{CardsDetail: []CardDetail{detail}},
+			GenerateCardTokenResponse{Token: "tok_1234567890"},
+			DeleteCardTokenRequest{Token: "tok_1234567890"},
+			ContinueRequest{AppToken: "secret-app-token"},
+			StatusRequest{AppToken: "secret-app-token"},
+			OptionsRequest{AppToken: "secret-app-token"},
+		}
+
+		for _, v := range values {
+			for _, format := range []string{"%v", "%+v", "%#v"} {
+				s := fmt.Sprintf(format, v)
+				assert.NotContains(t, s, "4111111111111111", format)
+				assert.NotContains(t, s, "tok_1234567890", format)
+				assert.NotContains(t, s, "secret-app-token", format)
+			}
+		}
+		assert.Contains(t, fmt.Sprintf("%+v", CancelRequest{CardsDetail: []CardDetail{detail}}), "411111******1111")
+	})
+}
+
+func TestLogging(t *testing.T) {
+	t.Run("should redact nested fields through slog handlers", func(t *testing.T) {
+		detail := CardDetail{MethodId: 1}
+		detail.Data.CardNumber = "4111111111111111"
+		values := []interface{}{
+			InitRequest{
+				Customer: Customer{ID: "1", Email: "john@example.com"},
+				PaymentData: PaymentData{PaymentMethods: []PaymentMethods{{
+					CardInfo: CardInfo{CardNumber: "4111111111111111", Token: "tok_1234567890"},
+				}}},
+			},
+			PaymentData{PaymentMethods: []PaymentMethods{{CardInfo: CardInfo{Token: "tok_1234567890"}}}},
+			NewSessionRequest{Customer: Customer{Email: "john@example.com"}},
+			ValidadeSessionResponse{Customer: Customer{Email: "john@example.com"}},
+			ListTokensResponse{Tokens: []TokenData{{Token: "tok_1234567890"}}},
+			CancelRequest{CardsDetail: []CardDetail{detail}},
+			FunctionRequest{Arguments: Arguments{GiftCard: GiftCard{CardNumber: "4111111111111111"}}},
+		}
+
+		for _, v := range values {
+			var buf bytes.Buffer
+			slog.New(slog.NewJSONHandler(&buf, nil)).Info("call", "req", v)
+			slog.New(slog.NewTextHandler(&buf, nil)).Info("call", "req", v)
+
+			out := buf.String()
+			assert.NotContains(t, out, "4111111111111111", "%T", v)
+			assert.NotContains(t, out, "tok_1234567890", "%T", v)
+			assert.NotContains(t, out, "john@example.com", "%T", v)
+		}
+	})
+
+	t.Run("should log calls without sensitive data", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"code": 1}`)),
+				Header:     http.Header{"Request-Id": []string{"trace-1"}},
+			}
+		})
+
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		api := newTestTokenClient(t, client, Config{Logger: logger})
+
+		_, err := api.BindCVV(BindCVVRequest{Token: "tok_1234567890", CVV: "cvv-secret"})
+		assert.NoError(t, err)
+
+		out := buf.String()
+		assert.Contains(t, out, `"endpoint":"Token.BindCVV"`)
+		assert.Contains(t, out, `"status":200`)
+		assert.Contains(t, out, `"trace_id":"trace-1"`)
+		assert.Contains(t, out, `"duration"`)
+		assert.NotContains(t, out, "tok_1234567890")
+		assert.NotContains(t, out, "cvv-secret")
+	})
+}
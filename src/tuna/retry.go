@@ -0,0 +1,177 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+)
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures how failed calls are retried. Idempotent endpoints
+// (ValidateSession, ListTokens, Status and Options) are retried by default.
+// Tuna does not deduplicate the other endpoints, so they are retried only when
+// the connection could not be established, unless RetryNonIdempotent is set.
+// Zero fields fall back to their defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles on every
+	// following retry up to MaxBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. A response asking through
+	// Retry-After to wait longer is not retried. Defaults to 5s.
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to the given fraction, e.g. 0.2 for ±20%.
+	Jitter float64
+	// StatusCodes are the HTTP status codes worth retrying. Defaults to 429,
+	// 502, 503 and 504.
+	StatusCodes []int
+	// RetryNonIdempotent retries Init, Capture, Cancel and the other
+	// non-idempotent endpoints like the idempotent ones. A retry after Tuna
+	// accepted the first attempt may charge or capture twice.
+	RetryNonIdempotent bool
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
+
+func (p *RetryPolicy) retryableStatus(code int) bool {
+	codes := p.StatusCodes
+	if codes == nil {
+		codes = defaultRetryStatusCodes
+	}
+
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff reports whether the outcome of the given attempt should be retried,
+// and how long to wait before doing so.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	switch {
+	case err != nil:
+		if !retryableError(err) {
+			return 0, false
+		}
+	case !p.retryableStatus(resp.StatusCode):
+		return 0, false
+	}
+
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	if resp != nil {
+		if delay, ok := retryAfter(resp); ok {
+			return delay, delay <= max
+		}
+	}
+
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+
+	delay := initial << uint(attempt-1)
+	if delay > max || delay <= 0 {
+		delay = max
+	}
+
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+
+	return delay, true
+}
+
+func (p *RetryPolicy) canRetry(e endpoint, err error) bool {
+	return e.idempotent || p.RetryNonIdempotent || notSent(err)
+}
+
+// notSent reports whether err proves the request never reached the server.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+func drainAndClose(resp *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
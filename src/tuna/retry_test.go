@@ -0,0 +1,165 @@
+package tuna
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSequenceClient(calls *int, statusCodes ...int) *http.Client {
+	return NewTestClient(func(req *http.Request) *http.Response {
+		code := statusCodes[*calls]
+		*calls++
+		return &http.Response{
+			StatusCode: code,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+			Header:     make(http.Header),
+		}
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	t.Run("should retry idempotent calls", func(t *testing.T) {
+		var calls int
+		api := newTestPaymentClient(t, newSequenceClient(&calls, 503, 502, 200), Config{Retry: policy})
+
+		_, err := api.Status(StatusRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should give up after max attempts", func(t *testing.T) {
+		var calls int
+		api := newTestPaymentClient(t, newSequenceClient(&calls, 503, 503, 503), Config{Retry: policy})
+
+		_, err := api.Options(OptionsRequest{})
+		assert.Error(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should not retry non-idempotent calls", func(t *testing.T) {
+		var calls int
+		api := newTestPaymentClient(t, newSequenceClient(&calls, 503, 200), Config{Retry: policy})
+
+		_, err := api.Init(InitRequest{})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should not retry non-idempotent calls with an idempotency key", func(t *testing.T) {
+		var calls int
+		api := newTestPaymentClient(t, newSequenceClient(&calls, 503, 200), Config{
+			Retry:            policy,
+			IdempotencyStore: NewMemoryIdempotencyStore(0),
+		})
+
+		_, err := api.Init(InitRequest{PartnerUniqueID: "order-1"})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should retry non-idempotent calls when opted in", func(t *testing.T) {
+		var calls int
+		api := newTestPaymentClient(t, newSequenceClient(&calls, 503, 200), Config{
+			Retry: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryNonIdempotent: true},
+		})
+
+		_, err := api.Init(InitRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should retry non-idempotent calls that never reached the server", func(t *testing.T) {
+		var calls int
+		client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{}`)), Header: make(http.Header)}, nil
+		})}
+		api := newTestPaymentClient(t, client, Config{Retry: policy})
+
+		_, err := api.Capture(CaptureRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should not retry non-idempotent calls after a read error", func(t *testing.T) {
+		var calls int
+		client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+		})}
+		api := newTestPaymentClient(t, client, Config{Retry: policy})
+
+		_, err := api.Capture(CaptureRequest{})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should not retry client errors", func(t *testing.T) {
+		var calls int
+		api := newTestPaymentClient(t, newSequenceClient(&calls, 400, 200), Config{Retry: policy})
+
+		_, err := api.Status(StatusRequest{})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should honor Retry-After", func(t *testing.T) {
+		resp := &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"3"}},
+		}
+
+		delay, ok := policy.backoff(1, resp, nil)
+		assert.True(t, ok)
+		assert.Equal(t, 3*time.Second, delay)
+	})
+
+	t.Run("should give up when Retry-After exceeds the max backoff", func(t *testing.T) {
+		var calls int
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     http.Header{"Retry-After": []string{"3600"}},
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{Retry: &RetryPolicy{MaxBackoff: time.Minute}})
+
+		start := time.Now()
+		_, err := api.Status(StatusRequest{})
+		var apiErr *APIError
+		assert.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 1, calls)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("should grow the backoff exponentially up to the max", func(t *testing.T) {
+		p := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header)}
+
+		delay, _ := p.backoff(2, resp, nil)
+		assert.Equal(t, 2*time.Second, delay)
+
+		delay, _ = p.backoff(3, resp, nil)
+		assert.Equal(t, 3*time.Second, delay)
+	})
+}
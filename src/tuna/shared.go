@@ -1,16 +1,13 @@
 package tuna
 
-const appTokenHeader = "x-tuna-apptoken"
-
-type Config struct {
-	BaseURL   string
-	UserAgent string
-	AppToken  string
-}
+const (
+	appTokenHeader       = "x-tuna-apptoken"
+	idempotencyKeyHeader = "Idempotency-Key"
+)
 
 type Customer struct {
 	ID    string `json:"id"`
-	Email string `json:"email"`
+	Email string `json:"email" redact:"email"`
 }
 
 type SessionCard struct {
@@ -28,7 +25,7 @@ type CardData struct {
 }
 
 type TokenData struct {
-	Token           string `json:"token"`
+	Token           string `json:"token" redact:"mask"`
 	Brand           string `json:"brand"`
 	CardHolderName  string `json:"cardHolderName"`
 	ExpirationMonth int    `json:"expirationMonth"`
@@ -56,18 +53,18 @@ type Address struct {
 }
 
 type BillingInfo struct {
-	Document     string  `json:"Document"`
+	Document     string  `json:"Document" redact:"mask"`
 	DocumentType string  `json:"DocumentType"`
 	Address      Address `json:"Address"`
 }
 
 type CardInfo struct {
-	CardNumber      interface{} `json:"CardNumber"`
+	CardNumber      interface{} `json:"CardNumber" redact:"pan"`
 	CardHolderName  string      `json:"CardHolderName"`
 	BrandName       string      `json:"BrandName"`
 	ExpirationMonth interface{} `json:"ExpirationMonth"`
 	ExpirationYear  interface{} `json:"ExpirationYear"`
-	Token           string      `json:"Token"`
+	Token           string      `json:"Token" redact:"mask"`
 	TokenSingleUse  int         `json:"TokenSingleUse"`
 	SaveCard        bool        `json:"SaveCard"`
 	BillingInfo     BillingInfo `json:"BillingInfo"`
@@ -127,7 +124,7 @@ type Method struct {
 	Message        Message        `json:"message"`
 	AdditionalInfo AdditionalInfo `json:"additionalInfo"`
 	MethodType     string         `json:"methodType"`
-	Status         string         `json:"status"`
+	Status         PaymentStatus  `json:"status"`
 	MethodId       int            `json:"methodId"`
 }
 
@@ -140,7 +137,7 @@ type CardDetail struct {
 	MethodId int `json:"MethodId"`
 	Amount   int `json:"Amount"`
 	Data     struct {
-		CardNumber string `json:"cardNumber"`
+		CardNumber string `json:"cardNumber" redact:"pan"`
 	} `json:"Data,omitempty"`
 }
 
@@ -150,21 +147,21 @@ type ItemDetail struct {
 }
 
 type Item struct {
-	Message         Message `json:"message"`
-	Status          string  `json:"Status"`
-	PartnerUniqueId string  `json:"PartnerUniqueId"`
-	MethodType      string  `json:"MethodType"`
+	Message         Message       `json:"message"`
+	Status          PaymentStatus `json:"Status"`
+	PartnerUniqueId string        `json:"PartnerUniqueId"`
+	MethodType      string        `json:"MethodType"`
 }
 
 type PaymentOption struct {
-	Name            string   `json:"name"`
-	DisplayName     string   `json:"displayName"`
-	AcceptedBrands  []string `json:"acceptedBrands"`
-	PaymentBehavior string   `json:"paymentBehavior"`
+	Name            string          `json:"name"`
+	DisplayName     string          `json:"displayName"`
+	AcceptedBrands  []string        `json:"acceptedBrands"`
+	PaymentBehavior PaymentBehavior `json:"paymentBehavior"`
 }
 
 type GiftCard struct {
-	CardNumber   string `json:"CardNumber"`
+	CardNumber   string `json:"CardNumber" redact:"pan"`
 	Organization string `json:"Organization"`
 }
 
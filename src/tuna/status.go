@@ -0,0 +1,178 @@
+package tuna
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PaymentStatus is the status code Tuna reports for a payment, one of its
+// methods or one of its items. Values not listed below are kept, see
+// unmarshalEnum.
+//
+// A payment moves through the statuses as follows:
+//
+//	Started    -> Pending, Authorized or Denied, answering Init
+//	Pending    -> Authorized or Denied, after Continue or asynchronously
+//	Authorized -> Captured, answering Capture
+//	Captured   -> Captured, answering Capture of the amount left
+//	Authorized -> Cancelled, answering Cancel
+//	Captured   -> Refunded, answering Cancel
+//
+// Denied, Cancelled and Refunded are terminal. Items cancelled through
+// CancelItem report Cancelled while the payment keeps its own status.
+type PaymentStatus string
+
+const (
+	PaymentStatusStarted    PaymentStatus = "0"
+	PaymentStatusAuthorized PaymentStatus = "1"
+	PaymentStatusCaptured   PaymentStatus = "2"
+	PaymentStatusRefunded   PaymentStatus = "3"
+	PaymentStatusDenied     PaymentStatus = "4"
+	PaymentStatusCancelled  PaymentStatus = "5"
+	PaymentStatusPending    PaymentStatus = "6"
+)
+
+var paymentStatusNames = map[PaymentStatus]string{
+	PaymentStatusStarted:    "started",
+	PaymentStatusAuthorized: "authorized",
+	PaymentStatusCaptured:   "captured",
+	PaymentStatusRefunded:   "refunded",
+	PaymentStatusDenied:     "denied",
+	PaymentStatusCancelled:  "cancelled",
+	PaymentStatusPending:    "pending",
+}
+
+func (s PaymentStatus) String() string {
+	if name, ok := paymentStatusNames[s]; ok {
+		return name
+	}
+	return "unknown(" + string(s) + ")"
+}
+
+func (s *PaymentStatus) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(data, (*string)(s))
+}
+
+// IsKnown reports whether s is one of the statuses listed above.
+func (s PaymentStatus) IsKnown() bool {
+	_, ok := paymentStatusNames[s]
+	return ok
+}
+
+// IsFinal reports whether Tuna is done processing the payment, i.e. it will
+// not change again unless the partner captures or cancels it.
+func (s PaymentStatus) IsFinal() bool {
+	switch s {
+	case PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusRefunded, PaymentStatusDenied, PaymentStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsApproved reports whether the payment was authorized, whether or not it
+// has been captured yet.
+func (s PaymentStatus) IsApproved() bool {
+	return s == PaymentStatusAuthorized || s == PaymentStatusCaptured
+}
+
+// IsPendingCustomerAction reports whether the payment waits on the customer,
+// e.g. for a 3DS challenge, before it can go on.
+func (s PaymentStatus) IsPendingCustomerAction() bool {
+	return s == PaymentStatusPending
+}
+
+type StatusResponse struct {
+	Status          PaymentStatus         `json:"status"`
+	PaymentKey      string                `json:"paymentKey"`
+	PartnerUniqueID string                `json:"partnerUniqueId"`
+	PaymentDate     Date                  `json:"paymentDate"`
+	Amount          int                   `json:"amount"`
+	Methods         []PaymentMethodStatus `json:"methods"`
+	Items           []PaymentItemStatus   `json:"items"`
+	Message         Message               `json:"message"`
+}
+
+// PaymentMethodStatus is the state of one payment method of a payment.
+type PaymentMethodStatus struct {
+	MethodID          int           `json:"methodId"`
+	MethodType        string        `json:"methodType"`
+	Status            PaymentStatus `json:"status"`
+	Amount            int           `json:"amount"`
+	CapturedAmount    int           `json:"capturedAmount"`
+	RefundedAmount    int           `json:"refundedAmount"`
+	Installments      int           `json:"installments"`
+	CardBrand         string        `json:"cardBrand"`
+	MaskedNumber      string        `json:"maskedNumber"`
+	AuthorizationCode string        `json:"authorizationCode"`
+	NSU               string        `json:"nsu"`
+	TID               string        `json:"tid"`
+	AuthorizationDate Date          `json:"authorizationDate"`
+	CaptureDate       Date          `json:"captureDate"`
+	CancelDate        Date          `json:"cancelDate"`
+	Refunds           []Refund      `json:"refunds"`
+	Message           Message       `json:"message"`
+}
+
+type Refund struct {
+	Amount int           `json:"amount"`
+	Date   Date          `json:"date"`
+	NSU    string        `json:"nsu"`
+	Status PaymentStatus `json:"status"`
+}
+
+// PaymentItemStatus is the state of one item of a payment.
+type PaymentItemStatus struct {
+	DetailUniqueID     string        `json:"detailUniqueId"`
+	ProductDescription string        `json:"productDescription"`
+	Amount             int           `json:"amount"`
+	ItemQuantity       int           `json:"itemQuantity"`
+	CancelledQuantity  int           `json:"cancelledQuantity"`
+	Status             PaymentStatus `json:"status"`
+}
+
+// dateLayouts are the layouts Tuna uses for dates. Dates without a zone are
+// read as UTC.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Date is a date reported by Tuna. It decodes dates with or without a zone,
+// and an empty string or null as the zero Date, which encodes back as "".
+type Date struct {
+	time.Time
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("tuna: invalid date %s", data)
+	}
+	if s == "" {
+		d.Time = time.Time{}
+		return nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			d.Time = t
+			return nil
+		}
+	}
+	return fmt.Errorf("tuna: invalid date %q", s)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(d.Format(time.RFC3339Nano))
+}
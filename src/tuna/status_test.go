@@ -0,0 +1,114 @@
+package tuna
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentStatus(t *testing.T) {
+	t.Run("should classify statuses", func(t *testing.T) {
+		assert.True(t, PaymentStatusCaptured.IsFinal())
+		assert.True(t, PaymentStatusCaptured.IsApproved())
+		assert.True(t, PaymentStatusAuthorized.IsApproved())
+		assert.False(t, PaymentStatusDenied.IsApproved())
+		assert.True(t, PaymentStatusDenied.IsFinal())
+		assert.False(t, PaymentStatusPending.IsFinal())
+		assert.True(t, PaymentStatusPending.IsPendingCustomerAction())
+		assert.False(t, PaymentStatusStarted.IsFinal())
+	})
+
+	t.Run("should keep unknown statuses", func(t *testing.T) {
+		s := PaymentStatus("X")
+		assert.False(t, s.IsKnown())
+		assert.False(t, s.IsFinal())
+		assert.Equal(t, "unknown(X)", s.String())
+		assert.Equal(t, "captured", PaymentStatusCaptured.String())
+	})
+}
+
+func TestPaymentClient_Status(t *testing.T) {
+	// Shaped like a real response: dates without a zone, empty or null dates
+	// for steps not reached yet.
+	body := `{
+		"status": "2",
+		"paymentKey": "key",
+		"partnerUniqueId": "order-1",
+		"paymentDate": "2024-05-01T10:00:00",
+		"amount": 150,
+		"methods": [{
+			"methodId": 0,
+			"methodType": "1",
+			"status": "3",
+			"amount": 150,
+			"capturedAmount": 150,
+			"refundedAmount": 50,
+			"installments": 3,
+			"authorizationCode": "123456",
+			"nsu": "nsu-1",
+			"tid": "tid-1",
+			"authorizationDate": "2024-05-01T10:00:01.123",
+			"captureDate": "",
+			"cancelDate": null,
+			"refunds": [{"amount": 50, "date": "2024-05-02T10:00:00-03:00", "status": "3"}]
+		}],
+		"items": [{"detailUniqueId": "a", "amount": 150, "itemQuantity": 2, "cancelledQuantity": 1, "status": "5"}],
+		"message": {"code": 1}
+	}`
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	paymentClient := newTestPaymentClient(t, client, Config{})
+	res, err := paymentClient.Status(StatusRequest{PartnerUniqueID: "order-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, PaymentStatusCaptured, res.Status)
+	assert.Equal(t, 150, res.Amount)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), res.PaymentDate.Time)
+	require.Len(t, res.Methods, 1)
+	m := res.Methods[0]
+	assert.Equal(t, PaymentStatusRefunded, m.Status)
+	assert.Equal(t, "123456", m.AuthorizationCode)
+	assert.Equal(t, "nsu-1", m.NSU)
+	assert.Equal(t, "tid-1", m.TID)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 1, 123000000, time.UTC), m.AuthorizationDate.Time)
+	assert.True(t, m.CaptureDate.IsZero())
+	assert.True(t, m.CancelDate.IsZero())
+	assert.Equal(t, 3, m.Installments)
+	require.Len(t, m.Refunds, 1)
+	assert.Equal(t, 50, m.Refunds[0].Amount)
+	assert.True(t, m.Refunds[0].Date.Equal(time.Date(2024, 5, 2, 13, 0, 0, 0, time.UTC)))
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, 1, res.Items[0].CancelledQuantity)
+	assert.Equal(t, PaymentStatusCancelled, res.Items[0].Status)
+}
+
+func TestDate(t *testing.T) {
+	t.Run("should round-trip dates", func(t *testing.T) {
+		var m PaymentMethodStatus
+		require.NoError(t, json.Unmarshal([]byte(`{"captureDate": "", "authorizationDate": "2024-05-01 10:00:00"}`), &m))
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), m.AuthorizationDate.Time)
+
+		data, err := json.Marshal(m)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"captureDate":""`)
+		assert.Contains(t, string(data), `"authorizationDate":"2024-05-01T10:00:00Z"`)
+	})
+
+	t.Run("should reject invalid dates", func(t *testing.T) {
+		var d Date
+		assert.Error(t, json.Unmarshal([]byte(`"yesterday"`), &d))
+		assert.Error(t, json.Unmarshal([]byte(`1`), &d))
+	})
+}
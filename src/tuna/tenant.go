@@ -0,0 +1,259 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrNoTenant      = errors.New("tuna: no tenant in context")
+	ErrUnknownTenant = errors.New("tuna: unknown tenant")
+)
+
+// Tenant holds the credentials and request defaults of one merchant.
+type Tenant struct {
+	ID string
+	// AppToken is sent for the tenant unless Credentials is set.
+	AppToken    string
+	Credentials CredentialProvider
+	// Account and PartnerID fill in the fields of the same name in requests
+	// that carry them and leave them empty.
+	Account   string
+	PartnerID int
+}
+
+func (t Tenant) credentials() CredentialProvider {
+	if t.Credentials != nil {
+		return t.Credentials
+	}
+	return StaticCredentials(t.AppToken)
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context routing calls to the tenant with the given ID.
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, id)
+}
+
+// TenantFromContext returns the tenant ID set by WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantContextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// TenantRegistry resolves the tenant of each call from its context. It is a
+// CredentialProvider sending the tenant's app token, and its Middleware fills
+// in the tenant's defaults. It is safe for concurrent use, including
+// registering tenants while calls are in flight.
+//
+//	registry := tuna.NewTenantRegistry(tenants...)
+//	client, err := tuna.NewClient(httpClient, registry.Configure(conf))
+//	...
+//	client.Payment().StatusWithContext(tuna.WithTenant(ctx, "store-1"), req)
+type TenantRegistry struct {
+	mu      sync.RWMutex
+	tenants map[string]Tenant
+}
+
+func NewTenantRegistry(tenants ...Tenant) *TenantRegistry {
+	r := &TenantRegistry{tenants: make(map[string]Tenant, len(tenants))}
+	for _, t := range tenants {
+		r.tenants[t.ID] = t
+	}
+	return r
+}
+
+// Register adds or replaces a tenant.
+func (r *TenantRegistry) Register(t Tenant) error {
+	if t.ID == "" {
+		return fmt.Errorf("%w: tenant ID is required", ErrInvalidConfig)
+	}
+	if t.AppToken == "" && t.Credentials == nil {
+		return fmt.Errorf("%w: tenant %q has no app token or credentials", ErrInvalidConfig, t.ID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tenants[t.ID] = t
+	return nil
+}
+
+func (r *TenantRegistry) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tenants, id)
+}
+
+func (r *TenantRegistry) Lookup(id string) (Tenant, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tenants[id]
+	return t, ok
+}
+
+// Resolve returns the tenant of ctx.
+func (r *TenantRegistry) Resolve(ctx context.Context) (Tenant, error) {
+	id, ok := TenantFromContext(ctx)
+	if !ok {
+		return Tenant{}, ErrNoTenant
+	}
+
+	t, ok := r.Lookup(id)
+	if !ok {
+		return Tenant{}, fmt.Errorf("%w: %q", ErrUnknownTenant, id)
+	}
+	return t, nil
+}
+
+func (r *TenantRegistry) AppToken(ctx context.Context) (string, error) {
+	t, err := r.Resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	return t.credentials().AppToken(ctx)
+}
+
+func (r *TenantRegistry) Refresh(ctx context.Context) error {
+	t, err := r.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	if refresher, ok := t.credentials().(CredentialRefresher); ok {
+		return refresher.Refresh(ctx)
+	}
+	return nil
+}
+
+// refreshStale lets concurrent 401s of a tenant share one refresh when its
+// provider supports it.
+func (r *TenantRegistry) refreshStale(ctx context.Context, stale string) error {
+	t, err := r.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	if refresher, ok := t.credentials().(CredentialRefresher); ok {
+		return refreshCredentials(ctx, refresher, stale)
+	}
+	return nil
+}
+
+// Configure returns a copy of conf sending the tenant's credentials and
+// defaults with every call.
+func (r *TenantRegistry) Configure(conf Config) Config {
+	conf.Credentials = r
+	conf.Middlewares = append([]Middleware{r.Middleware()}, conf.Middlewares...)
+	return conf
+}
+
+// Middleware fills in the AppToken, Account and PartnerID fields of requests
+// that carry them with the defaults of the tenant of the call. Fields already
+// set are left untouched. An AppToken filled in is replaced when the call is
+// sent again with refreshed credentials after a 401.
+func (r *TenantRegistry) Middleware() Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, endpoint Endpoint, request, response interface{}) error {
+			t, err := r.Resolve(ctx)
+			if err != nil {
+				return err
+			}
+
+			appToken, err := t.credentials().AppToken(ctx)
+			if err != nil {
+				return err
+			}
+
+			if r, ok := request.(appTokenRequest); ok && r.appToken() == "" {
+				ctx = context.WithValue(ctx, bodyAppTokenContextKey{}, true)
+			}
+
+			return next(ctx, endpoint, applyTenant(request, t, appToken), response)
+		}
+	}
+}
+
+func applyTenant(request interface{}, t Tenant, appToken string) interface{} {
+	switch r := request.(type) {
+	case CaptureRequest:
+		fillDefault(&r.AppToken, appToken)
+		fillDefault(&r.Account, t.Account)
+		return r
+	case ContinueRequest:
+		fillDefault(&r.AppToken, appToken)
+		fillDefault(&r.Account, t.Account)
+		return r
+	case StatusRequest:
+		fillDefault(&r.AppToken, appToken)
+		fillDefault(&r.Account, t.Account)
+		if r.PartnerID == 0 {
+			r.PartnerID = t.PartnerID
+		}
+		return r
+	case OptionsRequest:
+		fillDefault(&r.AppToken, appToken)
+		fillDefault(&r.Account, t.Account)
+		if r.PartnerID == 0 {
+			r.PartnerID = t.PartnerID
+		}
+		return r
+	default:
+		return request
+	}
+}
+
+func fillDefault(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+type bodyAppTokenContextKey struct{}
+
+// bodyAppToken reports whether the AppToken in the body of the call was
+// filled in from the credentials, and so must follow them when refreshed.
+func bodyAppToken(ctx context.Context) bool {
+	v, _ := ctx.Value(bodyAppTokenContextKey{}).(bool)
+	return v
+}
+
+// appTokenRequest is implemented by requests carrying the app token in their
+// body as well as in the header.
+type appTokenRequest interface {
+	appToken() string
+	withAppToken(token string) interface{}
+}
+
+func (r CaptureRequest) appToken() string { return r.AppToken }
+
+func (r CaptureRequest) withAppToken(token string) interface{} {
+	r.AppToken = token
+	return r
+}
+
+func (r ContinueRequest) appToken() string { return r.AppToken }
+
+func (r ContinueRequest) withAppToken(token string) interface{} {
+	r.AppToken = token
+	return r
+}
+
+func (r StatusRequest) appToken() string { return r.AppToken }
+
+func (r StatusRequest) withAppToken(token string) interface{} {
+	r.AppToken = token
+	return r
+}
+
+func (r OptionsRequest) appToken() string { return r.AppToken }
+
+func (r OptionsRequest) withAppToken(token string) interface{} {
+	r.AppToken = token
+	return r
+}
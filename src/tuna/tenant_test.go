@@ -0,0 +1,149 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantRegistry(t *testing.T) {
+	registry := NewTenantRegistry(
+		Tenant{ID: "store-1", AppToken: "token-1", Account: "account-1", PartnerID: 1},
+		Tenant{ID: "store-2", AppToken: "token-2", Account: "account-2", PartnerID: 2},
+	)
+
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		var body StatusRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, body.AppToken, req.Header.Get("x-tuna-apptoken"))
+		assert.Equal(t, "account-"+body.AppToken[len("token-"):], body.Account)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+			Header:     make(http.Header),
+		}
+	})
+	api := newTestPaymentClient(t, client, registry.Configure(Config{}))
+
+	t.Run("should route concurrent calls to their tenants", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			id := "store-1"
+			if i%2 == 0 {
+				id = "store-2"
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := api.StatusWithContext(WithTenant(context.Background(), id), StatusRequest{})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("should keep fields set by the caller", func(t *testing.T) {
+		req := applyTenant(OptionsRequest{Account: "custom"}, Tenant{Account: "default", PartnerID: 3}, "token")
+		assert.Equal(t, OptionsRequest{PartnerID: 3, AppToken: "token", Account: "custom"}, req)
+	})
+
+	t.Run("should fail without a known tenant", func(t *testing.T) {
+		_, err := api.Status(StatusRequest{})
+		assert.ErrorIs(t, err, ErrNoTenant)
+
+		_, err = api.StatusWithContext(WithTenant(context.Background(), "store-3"), StatusRequest{})
+		assert.ErrorIs(t, err, ErrUnknownTenant)
+	})
+
+	t.Run("should reject a tenant without credentials", func(t *testing.T) {
+		assert.ErrorIs(t, registry.Register(Tenant{ID: "store-3"}), ErrInvalidConfig)
+	})
+}
+
+func TestTenantRegistry_Idempotency(t *testing.T) {
+	registry := NewTenantRegistry(
+		Tenant{ID: "a", AppToken: "token-a"},
+		Tenant{ID: "b", AppToken: "token-b"},
+	)
+
+	var calls int
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		calls++
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"paymentKey": "key-` + req.Header.Get("x-tuna-apptoken") + `"}`)),
+			Header:     make(http.Header),
+		}
+	})
+	api := newTestPaymentClient(t, client, registry.Configure(Config{IdempotencyStore: NewMemoryIdempotencyStore(0)}))
+
+	t.Run("should not share responses between tenants", func(t *testing.T) {
+		for _, id := range []string{"a", "b", "a", "b"} {
+			res, err := api.InitWithContext(WithTenant(context.Background(), id), InitRequest{PartnerUniqueID: "1001"})
+			require.NoError(t, err)
+			assert.Equal(t, "key-token-"+id, res.PaymentKey)
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestTenantRegistry_Refresh(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		var body StatusRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, req.Header.Get("x-tuna-apptoken"), body.AppToken)
+		mu.Lock()
+		bodies = append(bodies, body.AppToken)
+		mu.Unlock()
+
+		code := http.StatusOK
+		if body.AppToken != "fresh" {
+			code = http.StatusUnauthorized
+		}
+		return &http.Response{
+			StatusCode: code,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+			Header:     make(http.Header),
+		}
+	})
+
+	var fetches int32
+	credentials := NewCallbackCredentials(func(ctx context.Context) (string, error) {
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			return "stale", nil
+		}
+		time.Sleep(10 * time.Millisecond)
+		return "fresh", nil
+	}, 0)
+	registry := NewTenantRegistry(Tenant{ID: "store-1", Credentials: credentials})
+	api := newTestPaymentClient(t, client, registry.Configure(Config{}))
+	ctx := WithTenant(context.Background(), "store-1")
+
+	_, err := registry.AppToken(ctx)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := api.StatusWithContext(ctx, StatusRequest{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+	assert.Contains(t, bodies, "fresh")
+}
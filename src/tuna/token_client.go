@@ -1,11 +1,8 @@
 package tuna
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
-	"net/url"
 	"time"
 )
 
@@ -18,223 +15,139 @@ const (
 	bindCVVPath           = "/api/Token/Bind"
 )
 
+const (
+	EndpointNewSession        Endpoint = "Token.NewSession"
+	EndpointValidateSession   Endpoint = "Token.ValidateSession"
+	EndpointGenerateCardToken Endpoint = "Token.GenerateCardToken"
+	EndpointListTokens        Endpoint = "Token.ListTokens"
+	EndpointDeleteCardToken   Endpoint = "Token.DeleteCardToken"
+	EndpointBindCVV           Endpoint = "Token.BindCVV"
+)
+
+var (
+	newSessionEndpoint        = endpoint{name: EndpointNewSession, method: http.MethodPost, path: newSessionPath}
+	validateSessionEndpoint   = endpoint{name: EndpointValidateSession, method: http.MethodPost, path: validateSessionPath, idempotent: true}
+	generateCardTokenEndpoint = endpoint{name: EndpointGenerateCardToken, method: http.MethodPost, path: generateCardTokenPath}
+	listTokensEndpoint        = endpoint{name: EndpointListTokens, method: http.MethodPost, path: listTokenPath, idempotent: true}
+	deleteCardTokenEndpoint   = endpoint{name: EndpointDeleteCardToken, method: http.MethodDelete, path: deleteCardTokenPath}
+	bindCVVEndpoint           = endpoint{name: EndpointBindCVV, method: http.MethodPost, path: bindCVVPath}
+)
+
 type TokenAPI interface {
 	NewSession(request NewSessionRequest) (*NewSessionResponse, error)
+	NewSessionWithContext(ctx context.Context, request NewSessionRequest) (*NewSessionResponse, error)
 	ValidateSession(request ValidadeSessionRequest) (*ValidadeSessionResponse, error)
+	ValidateSessionWithContext(ctx context.Context, request ValidadeSessionRequest) (*ValidadeSessionResponse, error)
 	GenerateCardToken(request GenerateCardTokenRequest) (*GenerateCardTokenResponse, error)
+	GenerateCardTokenWithContext(ctx context.Context, request GenerateCardTokenRequest) (*GenerateCardTokenResponse, error)
 	ListTokens(request ListTokensRequest) (*ListTokensResponse, error)
+	ListTokensWithContext(ctx context.Context, request ListTokensRequest) (*ListTokensResponse, error)
 	DeleteCardToken(request DeleteCardTokenRequest) (*DeleteCardTokenResponse, error)
+	DeleteCardTokenWithContext(ctx context.Context, request DeleteCardTokenRequest) (*DeleteCardTokenResponse, error)
 	BindCVV(request BindCVVRequest) (*BindCVVResponse, error)
+	BindCVVWithContext(ctx context.Context, request BindCVVRequest) (*BindCVVResponse, error)
 }
 
 type TokenClient struct {
-	baseURL    *url.URL
-	httpClient *http.Client
-	userAgent  string
-	appToken   string
+	transport *transport
 }
 
-func NewTokenClient(client *http.Client, conf Config) *TokenClient {
-	parsedURL, _ := url.Parse(conf.BaseURL)
-
-	return &TokenClient{
-		httpClient: client,
-		baseURL:    parsedURL,
-		userAgent:  conf.UserAgent,
-		appToken:   conf.AppToken,
-	}
-}
-
-func (c *TokenClient) NewSession(request NewSessionRequest) (*NewSessionResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
+// NewTokenClient returns a client for the base URL of conf. It fails if conf has
+// no app token or no valid base URL.
+func NewTokenClient(client *http.Client, conf Config) (*TokenClient, error) {
+	baseURL, err := conf.tokenBaseURL()
 	if err != nil {
 		return nil, err
 	}
-	rel := &url.URL{Path: newSessionPath}
-	u := c.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
+	if err := conf.validateCredentials(); err != nil {
 		return nil, err
 	}
 
-	setHeaders(req, c.userAgent, c.appToken)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
+	return &TokenClient{transport: newTransport(client, conf, baseURL)}, nil
+}
 
-	var nsr NewSessionResponse
-	err = json.NewDecoder(resp.Body).Decode(&nsr)
+// CircuitState reports the state of the circuit breaker. It is always
+// CircuitClosed when no circuit breaker is configured.
+func (c *TokenClient) CircuitState() CircuitState {
+	return c.transport.breaker.State()
+}
 
-	return &nsr, err
+func (c *TokenClient) NewSession(request NewSessionRequest) (*NewSessionResponse, error) {
+	return c.NewSessionWithContext(context.Background(), request)
 }
 
-func (c *TokenClient) ValidateSession(request ValidadeSessionRequest) (*ValidadeSessionResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
+func (c *TokenClient) NewSessionWithContext(ctx context.Context, request NewSessionRequest) (*NewSessionResponse, error) {
+	var nsr NewSessionResponse
+	if err := c.transport.do(ctx, newSessionEndpoint, request, &nsr); err != nil {
 		return nil, err
 	}
-	rel := &url.URL{Path: validateSessionPath}
-	u := c.baseURL.ResolveReference(rel)
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
+	return &nsr, nil
+}
 
-	setHeaders(req, c.userAgent, c.appToken)
+func (c *TokenClient) ValidateSession(request ValidadeSessionRequest) (*ValidadeSessionResponse, error) {
+	return c.ValidateSessionWithContext(context.Background(), request)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+func (c *TokenClient) ValidateSessionWithContext(ctx context.Context, request ValidadeSessionRequest) (*ValidadeSessionResponse, error) {
+	var vsr ValidadeSessionResponse
+	if err := c.transport.do(ctx, validateSessionEndpoint, request, &vsr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
 
-	var vsr ValidadeSessionResponse
-	err = json.NewDecoder(resp.Body).Decode(&vsr)
-
-	return &vsr, err
+	return &vsr, nil
 }
 
 func (c *TokenClient) GenerateCardToken(request GenerateCardTokenRequest) (*GenerateCardTokenResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: generateCardTokenPath}
-	u := c.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, c.userAgent, c.appToken)
+	return c.GenerateCardTokenWithContext(context.Background(), request)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+func (c *TokenClient) GenerateCardTokenWithContext(ctx context.Context, request GenerateCardTokenRequest) (*GenerateCardTokenResponse, error) {
+	var gctr GenerateCardTokenResponse
+	if err := c.transport.do(ctx, generateCardTokenEndpoint, request, &gctr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
 
-	var gctr GenerateCardTokenResponse
-	err = json.NewDecoder(resp.Body).Decode(&gctr)
-
-	return &gctr, err
+	return &gctr, nil
 }
 
 func (c *TokenClient) ListTokens(request ListTokensRequest) (*ListTokensResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: listTokenPath}
-	u := c.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, c.userAgent, c.appToken)
+	return c.ListTokensWithContext(context.Background(), request)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+func (c *TokenClient) ListTokensWithContext(ctx context.Context, request ListTokensRequest) (*ListTokensResponse, error) {
+	var ltr ListTokensResponse
+	if err := c.transport.do(ctx, listTokensEndpoint, request, &ltr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var ltr ListTokensResponse
-	err = json.NewDecoder(resp.Body).Decode(&ltr)
-
-	return &ltr, err
+	return &ltr, nil
 }
 
 func (c *TokenClient) DeleteCardToken(request DeleteCardTokenRequest) (*DeleteCardTokenResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: deleteCardTokenPath}
-	u := c.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodDelete, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, c.userAgent, c.appToken)
+	return c.DeleteCardTokenWithContext(context.Background(), request)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+func (c *TokenClient) DeleteCardTokenWithContext(ctx context.Context, request DeleteCardTokenRequest) (*DeleteCardTokenResponse, error) {
+	var dctr DeleteCardTokenResponse
+	if err := c.transport.do(ctx, deleteCardTokenEndpoint, request, &dctr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var dctr DeleteCardTokenResponse
-	err = json.NewDecoder(resp.Body).Decode(&dctr)
 
-	return &dctr, err
+	return &dctr, nil
 }
 
 func (c *TokenClient) BindCVV(request BindCVVRequest) (*BindCVVResponse, error) {
-	var buf = new(bytes.Buffer)
-	err := json.NewEncoder(buf).Encode(request)
-	if err != nil {
-		return nil, err
-	}
-	rel := &url.URL{Path: bindCVVPath}
-	u := c.baseURL.ResolveReference(rel)
-
-	req, err := http.NewRequest(http.MethodPost, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-
-	setHeaders(req, c.userAgent, c.appToken)
+	return c.BindCVVWithContext(context.Background(), request)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+func (c *TokenClient) BindCVVWithContext(ctx context.Context, request BindCVVRequest) (*BindCVVResponse, error) {
+	var bcr BindCVVResponse
+	if err := c.transport.do(ctx, bindCVVEndpoint, request, &bcr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("an error occurred, status code %v", resp.StatusCode)
-	}
-
-	var bcr BindCVVResponse
-	err = json.NewDecoder(resp.Body).Decode(&bcr)
-
-	return &bcr, err
+	return &bcr, nil
 }
 
 type NewSessionRequest struct {
@@ -266,7 +179,7 @@ type GenerateCardTokenRequest struct {
 }
 
 type GenerateCardTokenResponse struct {
-	Token     string `json:"token"`
+	Token     string `json:"token" redact:"mask"`
 	CardBrand string `json:"cardBrand"`
 	Code      int    `json:"code"`
 	Message   string `json:"message"`
@@ -283,20 +196,20 @@ type ListTokensResponse struct {
 }
 
 type DeleteCardTokenRequest struct {
-	Token     string `json:"token"`
+	Token     string `json:"token" redact:"mask"`
 	SessionID string `json:"sessionId"`
 }
 
 type DeleteCardTokenResponse struct {
-	Status  string `json:"status"`
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Status  DeleteStatus `json:"status"`
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
 }
 
 type BindCVVRequest struct {
-	Token     string `json:"token"`
+	Token     string `json:"token" redact:"mask"`
 	SessionID string `json:"sessionId"`
-	CVV       string `json:"CVV"`
+	CVV       string `json:"CVV" redact:"remove"`
 }
 
 type BindCVVResponse struct {
@@ -2,7 +2,10 @@ package tuna
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -23,11 +26,34 @@ func NewTestClient(fn RoundTripFunc) *http.Client {
 	}
 }
 
+// testConfig fills in the base URL and app token required by the clients.
+func testConfig(conf Config) Config {
+	if conf.BaseURL == "" {
+		conf.BaseURL = "https://tuna.test"
+	}
+	if conf.AppToken == "" {
+		conf.AppToken = "test"
+	}
+	return conf
+}
+
+func newTestTokenClient(t *testing.T, client *http.Client, conf Config) *TokenClient {
+	api, err := NewTokenClient(client, testConfig(conf))
+	require.NoError(t, err)
+	return api
+}
+
+func newTestPaymentClient(t *testing.T, client *http.Client, conf Config) *PaymentClient {
+	api, err := NewPaymentClient(client, testConfig(conf))
+	require.NoError(t, err)
+	return api
+}
+
 func TestClient_BindCVV(t *testing.T) {
 	t.Run("should return bind succeed", func(t *testing.T) {
 		client := NewTestClient(func(req *http.Request) *http.Response {
 			// Test request parameters
-			assert.Equal(t, req.URL.String(), "/api/Token/Bind")
+			assert.Equal(t, req.URL.String(), "https://tuna.test/api/Token/Bind")
 			return &http.Response{
 				StatusCode: 200,
 				// Send response to be tested
@@ -39,7 +65,7 @@ func TestClient_BindCVV(t *testing.T) {
 				Header: make(http.Header),
 			}
 		})
-		api := NewTokenClient(client, Config{})
+		api := newTestTokenClient(t, client, Config{})
 
 		req := BindCVVRequest{
 			Token:     "test",
@@ -57,7 +83,7 @@ func TestClient_BindCVV(t *testing.T) {
 	t.Run("should return session object invalid", func(t *testing.T) {
 		client := NewTestClient(func(req *http.Request) *http.Response {
 			// Test request parameters
-			assert.Equal(t, req.URL.String(), "/api/Token/Bind")
+			assert.Equal(t, req.URL.String(), "https://tuna.test/api/Token/Bind")
 			return &http.Response{
 				StatusCode: 200,
 				// Send response to be tested
@@ -69,7 +95,7 @@ func TestClient_BindCVV(t *testing.T) {
 				Header: make(http.Header),
 			}
 		})
-		api := NewTokenClient(client, Config{})
+		api := newTestTokenClient(t, client, Config{})
 
 		req := BindCVVRequest{
 			Token:     "test",
@@ -84,10 +110,33 @@ func TestClient_BindCVV(t *testing.T) {
 		}, res)
 	})
 
+	t.Run("should return invalid session error in strict mode", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{
+					"code": -1,
+					"message": "Session object is invalid"
+				}`)),
+				Header: make(http.Header),
+			}
+		})
+		api := newTestTokenClient(t, client, Config{Strict: true})
+
+		res, err := api.BindCVV(BindCVVRequest{})
+		assert.ErrorIs(t, err, ErrInvalidSession)
+		assert.Nil(t, res)
+
+		var businessErr *BusinessError
+		assert.True(t, errors.As(err, &businessErr))
+		assert.Equal(t, EndpointBindCVV, businessErr.Endpoint)
+		assert.Equal(t, "Session object is invalid", businessErr.Message.Message)
+	})
+
 	t.Run("should return a http error bad request", func(t *testing.T) {
 		client := NewTestClient(func(req *http.Request) *http.Response {
 			// Test request parameters
-			assert.Equal(t, req.URL.String(), "/api/Token/Bind")
+			assert.Equal(t, req.URL.String(), "https://tuna.test/api/Token/Bind")
 			return &http.Response{
 				StatusCode: http.StatusBadRequest,
 				// Send response to be tested
@@ -109,11 +158,44 @@ func TestClient_BindCVV(t *testing.T) {
 				Header: make(http.Header),
 			}
 		})
-		api := NewTokenClient(client, Config{})
+		api := newTestTokenClient(t, client, Config{})
 
 		req := BindCVVRequest{}
 		res, err := api.BindCVV(req)
 		assert.Error(t, err)
 		assert.Nil(t, res)
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, "One or more validation errors occurred.", apiErr.Title)
+		assert.Equal(t, "|9116a0f7-40600755b7d63ba3.", apiErr.TraceID)
+		assert.Equal(t, []string{"The value field is required."}, apiErr.Errors["value"])
+		assert.NotEmpty(t, apiErr.Body)
+	})
+}
+
+type testContextKey struct{}
+
+func TestClient_NewSessionWithContext(t *testing.T) {
+	t.Run("should send the request with the given context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), testContextKey{}, "value")
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			assert.Equal(t, "value", req.Context().Value(testContextKey{}))
+			return &http.Response{
+				StatusCode: 200,
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{
+					"sessionId": "session",
+					"code": 1,
+					"message": "Session created"
+				}`)),
+				Header: make(http.Header),
+			}
+		})
+		api := newTestTokenClient(t, client, Config{})
+
+		res, err := api.NewSessionWithContext(ctx, NewSessionRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "session", res.SessionID)
 	})
 }
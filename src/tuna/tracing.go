@@ -0,0 +1,87 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rodrigodev/tuna_go/src/tuna"
+
+// tracingMiddleware starts a client span named after the endpoint, e.g.
+// "tuna.Payment.Init", around every call.
+func tracingMiddleware(tracer trace.Tracer) Middleware {
+	return func(next Invoker) Invoker {
+		return func(ctx context.Context, endpoint Endpoint, request, response interface{}) error {
+			ctx, info := withCallInfo(ctx)
+			ctx, span := tracer.Start(ctx, "tuna."+string(endpoint),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attribute.String("tuna.endpoint", string(endpoint))),
+			)
+			defer span.End()
+
+			if id := stringField(request, "PartnerUniqueID"); id != "" {
+				span.SetAttributes(attribute.String("tuna.partner_unique_id", id))
+			}
+			if key := stringField(request, "PaymentKey"); key != "" {
+				span.SetAttributes(attribute.String("tuna.payment_key", key))
+			}
+
+			err := next(ctx, endpoint, request, response)
+
+			if info.statusCode != 0 {
+				span.SetAttributes(attribute.Int("http.response.status_code", info.statusCode))
+			}
+
+			if err != nil {
+				var businessErr *BusinessError
+				if errors.As(err, &businessErr) {
+					span.SetAttributes(attribute.Int("tuna.message.code", businessErr.Message.Code))
+				}
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return err
+			}
+
+			if key := stringField(response, "PaymentKey"); key != "" {
+				span.SetAttributes(attribute.String("tuna.payment_key", key))
+			}
+			if mc, ok := response.(messageCarrier); ok {
+				span.SetAttributes(attribute.Int("tuna.message.code", mc.tunaMessages()[0].Code))
+			}
+
+			return nil
+		}
+	}
+}
+
+// injectTraceHeaders propagates the trace context of ctx on outgoing requests.
+func (t *transport) injectTraceHeaders(ctx context.Context, req *http.Request) {
+	if t.propagator != nil {
+		t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+}
+
+// stringField returns the value of the string field name of the struct v, or
+// of the struct v points to.
+func stringField(v interface{}, name string) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+
+	f := rv.FieldByName(name)
+	if !f.IsValid() || f.Kind() != reflect.String {
+		return ""
+	}
+	return f.String()
+}
@@ -0,0 +1,82 @@
+package tuna
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	t.Run("should record a span per call and propagate the trace context", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			assert.NotEmpty(t, req.Header.Get("traceparent"))
+			return &http.Response{
+				StatusCode: 200,
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{
+					"paymentKey": "key-1",
+					"message": {"code": 1}
+				}`)),
+				Header: make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{TracerProvider: provider})
+
+		_, err := api.Init(InitRequest{PartnerUniqueID: "order-1"})
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "tuna.Payment.Init", spans[0].Name)
+		assert.Contains(t, spans[0].Attributes, attribute.String("tuna.partner_unique_id", "order-1"))
+		assert.Contains(t, spans[0].Attributes, attribute.String("tuna.payment_key", "key-1"))
+		assert.Contains(t, spans[0].Attributes, attribute.Int("http.response.status_code", 200))
+		assert.Contains(t, spans[0].Attributes, attribute.Int("tuna.message.code", 1))
+	})
+
+	t.Run("should mark the span of a failed call as an error", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{TracerProvider: provider})
+
+		_, err := api.Status(StatusRequest{PaymentKey: "key-1"})
+		assert.Error(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Contains(t, spans[0].Attributes, attribute.Int("http.response.status_code", 400))
+	})
+
+	t.Run("should not propagate headers without a tracer provider", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			assert.Empty(t, req.Header.Get("traceparent"))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{})
+
+		_, err := api.Options(OptionsRequest{})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,301 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// RequestHook is called with every outgoing request right before it is sent.
+// Returning an error aborts the call.
+type RequestHook func(req *http.Request) error
+
+// ResponseHook is called with every response before its status is checked and
+// its body decoded. Returning an error aborts the call.
+type ResponseHook func(resp *http.Response) error
+
+type endpoint struct {
+	name   Endpoint
+	method string
+	path   string
+	// idempotent endpoints are safe to retry after any retryable failure.
+	idempotent bool
+}
+
+// transport is the request executor shared by TokenClient and PaymentClient.
+type transport struct {
+	baseURL       *url.URL
+	httpClient    *http.Client
+	userAgent     string
+	credentials   CredentialProvider
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
+	strict        bool
+	retry         *RetryPolicy
+	idempotency   IdempotencyStore
+	limiters      *limiters
+	breaker       *circuitBreaker
+	middlewares   []Middleware
+	propagator    propagation.TextMapPropagator
+}
+
+func newTransport(client *http.Client, conf Config, baseURL *url.URL) *transport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var middlewares []Middleware
+	var propagator propagation.TextMapPropagator
+	if conf.TracerProvider != nil {
+		middlewares = append(middlewares, tracingMiddleware(conf.TracerProvider.Tracer(tracerName)))
+		propagator = conf.Propagator
+		if propagator == nil {
+			propagator = propagation.TraceContext{}
+		}
+	}
+	if conf.Logger != nil {
+		middlewares = append(middlewares, loggingMiddleware(conf.Logger))
+	}
+	if conf.Metrics != nil {
+		middlewares = append(middlewares, metricsMiddleware(conf.Metrics))
+	}
+	middlewares = append(middlewares, conf.Middlewares...)
+
+	return &transport{
+		httpClient:    client,
+		baseURL:       baseURL,
+		userAgent:     conf.UserAgent,
+		credentials:   conf.credentials(),
+		requestHooks:  conf.RequestHooks,
+		responseHooks: conf.ResponseHooks,
+		strict:        conf.Strict,
+		retry:         conf.Retry,
+		idempotency:   conf.IdempotencyStore,
+		limiters:      newLimiters(conf.RateLimit, conf.EndpointRateLimits),
+		breaker:       newCircuitBreaker(conf.CircuitBreaker),
+		middlewares:   middlewares,
+		propagator:    propagator,
+	}
+}
+
+// do runs the call to e through the middlewares.
+func (t *transport) do(ctx context.Context, e endpoint, in interface{}, out interface{}) error {
+	if len(t.middlewares) == 0 {
+		return t.call(ctx, e, in, out)
+	}
+
+	invoker := chain(t.middlewares, func(ctx context.Context, _ Endpoint, request, response interface{}) error {
+		return t.call(ctx, e, request, response)
+	})
+
+	return invoker(ctx, e.name, in, out)
+}
+
+// call encodes in as the JSON body of a request to e, sends it, retrying as
+// allowed by the retry policy, and decodes the response body into out.
+// Requests with an idempotency key are deduplicated through the idempotency
+// store, if one is configured.
+func (t *transport) call(ctx context.Context, e endpoint, in interface{}, out interface{}) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	key := t.idempotencyKey(ctx, in)
+	if key == "" || t.idempotency == nil {
+		body, err := t.execute(ctx, e, in, payload, key)
+		if err != nil {
+			return err
+		}
+		return t.decode(e, body, out)
+	}
+
+	storeKey := string(e.name) + ":" + key
+	// A store may be shared by the tenants of a TenantRegistry, which reuse
+	// each other's PartnerUniqueIDs.
+	if id, ok := TenantFromContext(ctx); ok {
+		storeKey = "tenant:" + id + ":" + storeKey
+	}
+	stored, found, err := t.idempotency.Begin(ctx, storeKey)
+	if err != nil {
+		return err
+	}
+	if found {
+		return t.decode(e, stored, out)
+	}
+
+	body, err := t.execute(ctx, e, in, payload, key)
+	if err != nil {
+		t.idempotency.Abort(context.Background(), storeKey)
+		return err
+	}
+
+	// Tuna has accepted the call by now, so failing to store its response must
+	// not fail the call; releasing the key keeps duplicates from blocking.
+	if err := t.idempotency.Complete(ctx, storeKey, body); err != nil {
+		t.idempotency.Abort(context.Background(), storeKey)
+	}
+
+	return t.decode(e, body, out)
+}
+
+// execute sends payload, the encoding of in, to e until it succeeds or the
+// retry policy gives up, and returns the body of the successful response.
+func (t *transport) execute(ctx context.Context, e endpoint, in interface{}, payload []byte, key string) ([]byte, error) {
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		req, err := t.newRequest(ctx, e, payload, key)
+		if err != nil {
+			return nil, err
+		}
+
+		release, err := t.limiters.acquire(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := t.breaker.allow(); err != nil {
+			release()
+			return nil, err
+		}
+
+		resp, err := t.httpClient.Do(req)
+		t.breaker.record(ctx, resp, err)
+		if err == nil {
+			recordResponse(ctx, resp)
+			if err := t.runResponseHooks(resp); err != nil {
+				release()
+				return nil, err
+			}
+		}
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && !refreshed {
+			if r, ok := t.credentials.(CredentialRefresher); ok {
+				drainAndClose(resp)
+				release()
+				refreshed = true
+				if err := refreshCredentials(ctx, r, req.Header.Get(appTokenHeader)); err != nil {
+					return nil, err
+				}
+				if payload, err = t.refreshPayload(ctx, in, payload); err != nil {
+					return nil, err
+				}
+				attempt--
+				continue
+			}
+		}
+
+		if t.retry != nil && attempt < t.retry.maxAttempts() && t.retry.canRetry(e, err) {
+			if delay, ok := t.retry.backoff(attempt, resp, err); ok {
+				if resp != nil {
+					drainAndClose(resp)
+				}
+				release()
+				if err := sleep(ctx, delay); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		body, err := readBody(resp)
+		release()
+		return body, err
+	}
+}
+
+// refreshPayload encodes in again with the refreshed app token when its body
+// carries the one of the credentials.
+func (t *transport) refreshPayload(ctx context.Context, in interface{}, payload []byte) ([]byte, error) {
+	r, ok := in.(appTokenRequest)
+	if !ok || !bodyAppToken(ctx) {
+		return payload, nil
+	}
+
+	appToken, err := t.credentials.AppToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(r.withAppToken(appToken))
+}
+
+func (t *transport) newRequest(ctx context.Context, e endpoint, payload []byte, key string) (*http.Request, error) {
+	rel := &url.URL{Path: e.path}
+	u := t.baseURL.ResolveReference(rel)
+
+	req, err := http.NewRequestWithContext(ctx, e.method, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	appToken, err := t.credentials.AppToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	setHeaders(req, t.userAgent, appToken)
+	t.injectTraceHeaders(ctx, req)
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	for _, hook := range t.requestHooks {
+		if err := hook(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+func (t *transport) runResponseHooks(resp *http.Response) error {
+	for _, hook := range t.responseHooks {
+		if err := hook(resp); err != nil {
+			resp.Body.Close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (t *transport) decode(e endpoint, body []byte, out interface{}) error {
+	if err := json.Unmarshal(body, out); err != nil {
+		return err
+	}
+
+	if t.strict {
+		return checkBusinessError(e.name, out)
+	}
+
+	return nil
+}
+
+// withBaseURL returns a copy of t sending requests to baseURL. The copy shares
+// everything with t but its circuit breaker.
+func (t *transport) withBaseURL(baseURL *url.URL, breaker *circuitBreaker) *transport {
+	c := *t
+	c.baseURL = baseURL
+	c.breaker = breaker
+	return &c
+}
@@ -0,0 +1,133 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport_Hooks(t *testing.T) {
+	t.Run("should run request and response hooks", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			assert.Equal(t, "hooked", req.Header.Get("X-Test"))
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"code": 1}`)),
+				Header:     http.Header{"X-Response": []string{"seen"}},
+			}
+		})
+
+		var seen string
+		api := newTestTokenClient(t, client, Config{
+			RequestHooks: []RequestHook{func(req *http.Request) error {
+				req.Header.Set("X-Test", "hooked")
+				return nil
+			}},
+			ResponseHooks: []ResponseHook{func(resp *http.Response) error {
+				seen = resp.Header.Get("X-Response")
+				return nil
+			}},
+		})
+
+		_, err := api.BindCVVWithContext(context.Background(), BindCVVRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "seen", seen)
+	})
+
+	t.Run("should abort when a request hook fails", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			t.Fatal("request should not be sent")
+			return nil
+		})
+
+		hookErr := errors.New("hook failed")
+		api := newTestPaymentClient(t, client, Config{
+			RequestHooks: []RequestHook{func(req *http.Request) error {
+				return hookErr
+			}},
+		})
+
+		res, err := api.Options(OptionsRequest{})
+		assert.ErrorIs(t, err, hookErr)
+		assert.Nil(t, res)
+	})
+}
+
+func TestTransport_APIError(t *testing.T) {
+	t.Run("should decode a Tuna message from an error body", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{
+					"message": {"source": 1, "code": -5, "message": "Unexpected failure"}
+				}`)),
+				Header: make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{})
+
+		_, err := api.Init(InitRequest{})
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, Message{Source: 1, Code: -5, Message: "Unexpected failure"}, apiErr.Message)
+		assert.Equal(t, "an error occurred, status code 500: Unexpected failure", apiErr.Error())
+	})
+
+	t.Run("should keep a body that is not JSON", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`Bad Gateway`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{})
+
+		_, err := api.Status(StatusRequest{})
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Equal(t, []byte("Bad Gateway"), apiErr.Body)
+	})
+}
+
+func TestTransport_Strict(t *testing.T) {
+	t.Run("should return declined error for a failed payment method", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Body: ioutil.NopCloser(bytes.NewBufferString(`{
+					"status": "4",
+					"message": {"code": 1},
+					"methods": [{"message": {"code": -3, "message": "Not authorized"}}]
+				}`)),
+				Header: make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{Strict: true})
+
+		_, err := api.Init(InitRequest{})
+		assert.ErrorIs(t, err, ErrDeclined)
+	})
+
+	t.Run("should map unknown negative codes to request failed", func(t *testing.T) {
+		client := NewTestClient(func(req *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message": {"code": -99}}`)),
+				Header:     make(http.Header),
+			}
+		})
+		api := newTestPaymentClient(t, client, Config{Strict: true})
+
+		_, err := api.Options(OptionsRequest{})
+		assert.ErrorIs(t, err, ErrRequestFailed)
+	})
+}
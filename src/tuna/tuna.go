@@ -1,15 +1,27 @@
 package tuna
 
+import "context"
+
+// PaymentAdapter is a service layer over the token and payment APIs covering
+// the checkout lifecycle. Unlike the clients, its methods other than
+// NewSession return a *BusinessError when Tuna reports a failed message in a
+// 200 response, along with the response itself.
 type PaymentAdapter struct {
-	tokenClient   *TokenClient
-	paymentClient *PaymentClient
+	tokenAPI   TokenAPI
+	paymentAPI PaymentAPI
 }
 
-func NewTunaService(tokenClient *TokenClient, paymentClient *PaymentClient) *PaymentAdapter {
-	return &PaymentAdapter{tokenClient: tokenClient, paymentClient: paymentClient}
+// NewTunaService returns a PaymentAdapter backed by tokenAPI and paymentAPI,
+// which may be the clients of this package or any fake or decorator of them.
+func NewTunaService(tokenAPI TokenAPI, paymentAPI PaymentAPI) *PaymentAdapter {
+	return &PaymentAdapter{tokenAPI: tokenAPI, paymentAPI: paymentAPI}
 }
 
 func (s *PaymentAdapter) NewSession(userID string, email string) (string, error) {
+	return s.NewSessionWithContext(context.Background(), userID, email)
+}
+
+func (s *PaymentAdapter) NewSessionWithContext(ctx context.Context, userID string, email string) (string, error) {
 	request := NewSessionRequest{
 		Customer: Customer{
 			ID:    userID,
@@ -17,10 +29,125 @@ func (s *PaymentAdapter) NewSession(userID string, email string) (string, error)
 		},
 	}
 
-	session, err := s.tokenClient.NewSession(request)
+	session, err := s.tokenAPI.NewSessionWithContext(ctx, request)
 	if err != nil {
 		return "", err
 	}
 
 	return session.SessionID, nil
-}
\ No newline at end of file
+}
+
+// ValidateSession returns the customer and saved cards of sessionID.
+func (s *PaymentAdapter) ValidateSession(ctx context.Context, sessionID string) (*ValidadeSessionResponse, error) {
+	return s.tokenAPI.ValidateSessionWithContext(ctx, ValidadeSessionRequest{SessionID: sessionID})
+}
+
+// TokenizeCard stores card under sessionID and returns its token.
+func (s *PaymentAdapter) TokenizeCard(ctx context.Context, sessionID string, card CardData) (*GenerateCardTokenResponse, error) {
+	res, err := s.tokenAPI.GenerateCardTokenWithContext(ctx, GenerateCardTokenRequest{SessionID: sessionID, Card: card})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, checkBusinessError(EndpointGenerateCardToken, res)
+}
+
+// ListCards returns the tokens saved under sessionID.
+func (s *PaymentAdapter) ListCards(ctx context.Context, sessionID string) ([]TokenData, error) {
+	res, err := s.tokenAPI.ListTokensWithContext(ctx, ListTokensRequest{SessionID: sessionID})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Tokens, checkBusinessError(EndpointListTokens, res)
+}
+
+// DeleteCard removes token from sessionID.
+func (s *PaymentAdapter) DeleteCard(ctx context.Context, sessionID string, token string) error {
+	res, err := s.tokenAPI.DeleteCardTokenWithContext(ctx, DeleteCardTokenRequest{Token: token, SessionID: sessionID})
+	if err != nil {
+		return err
+	}
+
+	return checkBusinessError(EndpointDeleteCardToken, res)
+}
+
+// BindCVV attaches cvv to a saved token so it can be used in a payment.
+func (s *PaymentAdapter) BindCVV(ctx context.Context, sessionID string, token string, cvv string) error {
+	res, err := s.tokenAPI.BindCVVWithContext(ctx, BindCVVRequest{Token: token, SessionID: sessionID, CVV: cvv})
+	if err != nil {
+		return err
+	}
+
+	return checkBusinessError(EndpointBindCVV, res)
+}
+
+// Pay starts the payment described by request.
+func (s *PaymentAdapter) Pay(ctx context.Context, request InitRequest) (*InitResponse, error) {
+	res, err := s.paymentAPI.InitWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, checkBusinessError(EndpointInit, res)
+}
+
+// Continue resumes a payment waiting on the customer, e.g. after 3DS.
+func (s *PaymentAdapter) Continue(ctx context.Context, request ContinueRequest) (*ContinueResponse, error) {
+	res, err := s.paymentAPI.ContinueWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, checkBusinessError(EndpointContinue, res)
+}
+
+// Capture captures an authorized payment.
+func (s *PaymentAdapter) Capture(ctx context.Context, request CaptureRequest) (*CaptureResponse, error) {
+	res, err := s.paymentAPI.CaptureWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, checkBusinessError(EndpointCapture, res)
+}
+
+// Cancel cancels or refunds a payment, entirely or per card.
+func (s *PaymentAdapter) Cancel(ctx context.Context, request CancelRequest) (*CancelResponse, error) {
+	res, err := s.paymentAPI.CancelWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, checkBusinessError(EndpointCancel, res)
+}
+
+// CancelItems cancels some items of a payment.
+func (s *PaymentAdapter) CancelItems(ctx context.Context, request CancelItemRequest) (*CancelItemResponse, error) {
+	res, err := s.paymentAPI.CancelItemWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, checkBusinessError(EndpointCancelItem, res)
+}
+
+// Status returns the current state of a payment.
+func (s *PaymentAdapter) Status(ctx context.Context, request StatusRequest) (*StatusResponse, error) {
+	res, err := s.paymentAPI.StatusWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, checkBusinessError(EndpointStatus, res)
+}
+
+// PaymentOptions returns the payment options available to the partner.
+func (s *PaymentAdapter) PaymentOptions(ctx context.Context, request OptionsRequest) ([]PaymentOption, error) {
+	res, err := s.paymentAPI.OptionsWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.PaymentOptions, checkBusinessError(EndpointOptions, res)
+}
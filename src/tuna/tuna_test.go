@@ -0,0 +1,132 @@
+package tuna_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+	"github.com/rodrigodev/tuna_go/src/tuna/tunamock"
+	"github.com/rodrigodev/tuna_go/src/tuna/tunatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentAdapter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should run a checkout against any TokenAPI and PaymentAPI", func(t *testing.T) {
+		token := tunamock.NewTokenAPI()
+		token.OnNewSession().Return(&tuna.NewSessionResponse{SessionID: "session", Code: 1}, nil).Once()
+		token.OnGenerateCardToken().Return(&tuna.GenerateCardTokenResponse{Token: "card-token", Code: 1}, nil).Once()
+		payment := tunamock.NewPaymentAPI()
+		payment.OnInit().Return(&tuna.InitResponse{PaymentKey: "key"}, nil).Once()
+
+		service := tuna.NewTunaService(token, payment)
+
+		sessionID, err := service.NewSession("1", "john@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "session", sessionID)
+
+		card, err := service.TokenizeCard(ctx, sessionID, tuna.CardData{CardHolderName: "John"})
+		require.NoError(t, err)
+		assert.Equal(t, "card-token", card.Token)
+
+		res, err := service.Pay(ctx, tuna.InitRequest{PartnerUniqueID: "order-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "key", res.PaymentKey)
+
+		assert.True(t, token.AssertExpectations(t))
+		assert.True(t, payment.AssertExpectations(t))
+	})
+
+	t.Run("should turn failed messages into business errors", func(t *testing.T) {
+		token := tunamock.NewTokenAPI()
+		token.OnBindCVV().Return(&tuna.BindCVVResponse{Code: -2, Message: "invalid token"}, nil)
+		payment := tunamock.NewPaymentAPI()
+		payment.OnCapture().Return(&tuna.CaptureResponse{Message: tuna.Message{Code: -3}}, nil)
+
+		service := tuna.NewTunaService(token, payment)
+
+		err := service.BindCVV(ctx, "session", "card-token", "123")
+		assert.ErrorIs(t, err, tuna.ErrInvalidToken)
+
+		res, err := service.Capture(ctx, tuna.CaptureRequest{PartnerUniqueID: "order-1"})
+		require.NotNil(t, res)
+		assert.Equal(t, -3, res.Message.Code)
+		var be *tuna.BusinessError
+		require.ErrorAs(t, err, &be)
+		assert.Equal(t, tuna.EndpointCapture, be.Endpoint)
+		assert.ErrorIs(t, err, tuna.ErrDeclined)
+	})
+
+	t.Run("should keep the session contract of the token API", func(t *testing.T) {
+		token := tunamock.NewTokenAPI()
+		token.OnNewSession().Return(&tuna.NewSessionResponse{Code: -1, Message: "invalid customer"}, nil)
+
+		sessionID, err := tuna.NewTunaService(token, tunamock.NewPaymentAPI()).NewSession("1", "john@example.com")
+		assert.NoError(t, err)
+		assert.Empty(t, sessionID)
+	})
+
+	t.Run("should return a declined payment with its error", func(t *testing.T) {
+		s := tunatest.NewServer()
+		defer s.Close()
+		s.SetOutcome(tunatest.Decline)
+		client, err := tuna.NewClient(s.Client(), s.Config())
+		require.NoError(t, err)
+
+		res, err := client.Service().Pay(ctx, tuna.InitRequest{
+			PartnerUniqueID: "order-1",
+			PaymentData: tuna.PaymentData{PaymentMethods: []tuna.PaymentMethods{{
+				PaymentMethodType: "1",
+				Amount:            100,
+			}}},
+		})
+		assert.ErrorIs(t, err, tuna.ErrDeclined)
+		require.NotNil(t, res)
+		assert.NotEmpty(t, res.PaymentKey)
+		assert.Equal(t, tuna.PaymentStatusDenied, res.Status)
+	})
+
+	t.Run("should cover the lifecycle against the fake server", func(t *testing.T) {
+		s := tunatest.NewServer()
+		defer s.Close()
+		client, err := tuna.NewClient(s.Client(), s.Config())
+		require.NoError(t, err)
+		service := client.Service()
+
+		sessionID, err := service.NewSessionWithContext(ctx, "1", "john@example.com")
+		require.NoError(t, err)
+
+		card, err := service.TokenizeCard(ctx, sessionID, tuna.CardData{CardHolderName: "John"})
+		require.NoError(t, err)
+		require.NoError(t, service.BindCVV(ctx, sessionID, card.Token, "123"))
+
+		cards, err := service.ListCards(ctx, sessionID)
+		require.NoError(t, err)
+		assert.Len(t, cards, 1)
+
+		_, err = service.Pay(ctx, tuna.InitRequest{
+			PartnerUniqueID: "order-1",
+			PaymentData: tuna.PaymentData{PaymentMethods: []tuna.PaymentMethods{{
+				PaymentMethodType: "1",
+				Amount:            100,
+			}}},
+		})
+		require.NoError(t, err)
+
+		_, err = service.Capture(ctx, tuna.CaptureRequest{PartnerUniqueID: "order-1", Amount: 100})
+		require.NoError(t, err)
+
+		_, err = service.Cancel(ctx, tuna.CancelRequest{PartnerUniqueID: "order-1", CancelAll: true})
+		require.NoError(t, err)
+
+		status, _ := s.PaymentStatus("order-1")
+		assert.Equal(t, tunatest.StatusRefunded, status)
+
+		require.NoError(t, service.DeleteCard(ctx, sessionID, card.Token))
+		cards, err = service.ListCards(ctx, sessionID)
+		require.NoError(t, err)
+		assert.Empty(t, cards)
+	})
+}
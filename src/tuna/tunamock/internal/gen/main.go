@@ -0,0 +1,97 @@
+// Command gen writes the tunamock mocks of tuna.TokenAPI and tuna.PaymentAPI
+// from the method sets of the interfaces, keeping them in sync.
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+type method struct {
+	Name     string
+	Request  string
+	Response string
+}
+
+type mock struct {
+	Name    string
+	Methods []method
+}
+
+var tmpl = template.Must(template.New("mock").Parse(`// Code generated by tunamock/internal/gen. DO NOT EDIT.
+
+package tunamock
+
+import (
+	"context"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+// {{.Name}} is a programmable mock of tuna.{{.Name}}.
+type {{.Name}} struct {
+	mock
+}
+
+var _ tuna.{{.Name}} = (*{{.Name}})(nil)
+
+func New{{.Name}}() *{{.Name}} {
+	return &{{.Name}}{}
+}
+{{range .Methods}}
+// On{{.Name}} sets an expectation for {{.Name}} and {{.Name}}WithContext.
+func (m *{{$.Name}}) On{{.Name}}() *Expectation[tuna.{{.Request}}, tuna.{{.Response}}] {
+	return expect[tuna.{{.Request}}, tuna.{{.Response}}](&m.mock, "{{.Name}}")
+}
+
+func (m *{{$.Name}}) {{.Name}}(request tuna.{{.Request}}) (*tuna.{{.Response}}, error) {
+	return m.{{.Name}}WithContext(context.Background(), request)
+}
+
+func (m *{{$.Name}}) {{.Name}}WithContext(ctx context.Context, request tuna.{{.Request}}) (*tuna.{{.Response}}, error) {
+	return call[tuna.{{.Request}}, tuna.{{.Response}}](&m.mock, ctx, "{{.Name}}", request)
+}
+{{end}}`))
+
+func main() {
+	generate("token.go", "TokenAPI", reflect.TypeOf((*tuna.TokenAPI)(nil)).Elem())
+	generate("payment.go", "PaymentAPI", reflect.TypeOf((*tuna.PaymentAPI)(nil)).Elem())
+}
+
+// generate writes the mock of iface to path, one On method and two call
+// methods per method of iface without the WithContext suffix.
+func generate(path, name string, iface reflect.Type) {
+	m := mock{Name: name}
+	for i := 0; i < iface.NumMethod(); i++ {
+		fn := iface.Method(i)
+		if strings.HasSuffix(fn.Name, "WithContext") {
+			continue
+		}
+		m.Methods = append(m.Methods, method{
+			Name:     fn.Name,
+			Request:  fn.Type.In(0).Name(),
+			Response: fn.Type.Out(0).Elem().Name(),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, m); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package tunamock provides programmable mocks of tuna.TokenAPI and
+// tuna.PaymentAPI.
+//
+//	token := tunamock.NewTokenAPI()
+//	token.OnNewSession().Return(&tuna.NewSessionResponse{SessionID: "session"}, nil).Once()
+//	service := tuna.NewTunaService(token, payment)
+//	...
+//	token.AssertExpectations(t)
+package tunamock
+
+//go:generate go run ./internal/gen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnexpectedCall is returned by a mock called without a matching
+// expectation.
+var ErrUnexpectedCall = errors.New("tunamock: unexpected call")
+
+// Call is a call received by a mock.
+type Call struct {
+	Method  string
+	Request interface{}
+}
+
+// TestingT is the subset of testing.T used by AssertExpectations.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Expectation describes how a mock answers calls to one method. Expectations
+// are matched in the order they were set.
+type Expectation[Req, Res any] struct {
+	method string
+	match  func(Req) bool
+	fn     func(context.Context, Req) (*Res, error)
+	res    *Res
+	err    error
+	times  int
+	calls  int
+}
+
+// With restricts the expectation to requests accepted by match.
+func (e *Expectation[Req, Res]) With(match func(Req) bool) *Expectation[Req, Res] {
+	e.match = match
+	return e
+}
+
+// Return makes matching calls return res and err.
+func (e *Expectation[Req, Res]) Return(res *Res, err error) *Expectation[Req, Res] {
+	e.res, e.err = res, err
+	return e
+}
+
+// Do makes matching calls run fn.
+func (e *Expectation[Req, Res]) Do(fn func(context.Context, Req) (*Res, error)) *Expectation[Req, Res] {
+	e.fn = fn
+	return e
+}
+
+// Times limits the expectation to n calls, all of which must happen. Without
+// it the expectation matches any number of calls, at least one.
+func (e *Expectation[Req, Res]) Times(n int) *Expectation[Req, Res] {
+	e.times = n
+	return e
+}
+
+func (e *Expectation[Req, Res]) Once() *Expectation[Req, Res] {
+	return e.Times(1)
+}
+
+func (e *Expectation[Req, Res]) check() error {
+	switch {
+	case e.times > 0 && e.calls != e.times:
+		return fmt.Errorf("%s: expected %d calls, got %d", e.method, e.times, e.calls)
+	case e.times == 0 && e.calls == 0:
+		return fmt.Errorf("%s: expected at least one call, got none", e.method)
+	}
+	return nil
+}
+
+type expectation interface {
+	check() error
+}
+
+// mock records calls and holds the expectations of a mock.
+type mock struct {
+	mu           sync.Mutex
+	expectations []expectation
+	calls        []Call
+}
+
+func expect[Req, Res any](m *mock, method string) *Expectation[Req, Res] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &Expectation[Req, Res]{method: method}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+func call[Req, Res any](m *mock, ctx context.Context, method string, req Req) (*Res, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: method, Request: req})
+
+	for _, raw := range m.expectations {
+		e, ok := raw.(*Expectation[Req, Res])
+		if !ok || e.method != method {
+			continue
+		}
+		if e.times > 0 && e.calls >= e.times {
+			continue
+		}
+		if e.match != nil && !e.match(req) {
+			continue
+		}
+
+		e.calls++
+		m.mu.Unlock()
+
+		if e.fn != nil {
+			return e.fn(ctx, req)
+		}
+		return e.res, e.err
+	}
+
+	m.mu.Unlock()
+	return nil, fmt.Errorf("%w: %s(%+v)", ErrUnexpectedCall, method, req)
+}
+
+// Calls returns every call received so far.
+func (m *mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls received for method, e.g. "Init". Calls to a
+// method and to its WithContext variant are both recorded under the method.
+func (m *mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, c := range m.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// AssertExpectations fails t for every expectation that was not met.
+func (m *mock) AssertExpectations(t TestingT) bool {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := true
+	for _, e := range m.expectations {
+		if err := e.check(); err != nil {
+			t.Errorf("%v", err)
+			ok = false
+		}
+	}
+	return ok
+}
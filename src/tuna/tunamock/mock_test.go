@@ -0,0 +1,78 @@
+package tunamock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMocks(t *testing.T) {
+	t.Run("should answer with canned responses and record calls", func(t *testing.T) {
+		token := NewTokenAPI()
+		token.OnNewSession().Return(&tuna.NewSessionResponse{SessionID: "session"}, nil).Once()
+
+		res, err := token.NewSession(tuna.NewSessionRequest{Customer: tuna.Customer{ID: "1"}})
+		require.NoError(t, err)
+		assert.Equal(t, "session", res.SessionID)
+
+		calls := token.CallsTo("NewSession")
+		require.Len(t, calls, 1)
+		assert.Equal(t, "1", calls[0].Request.(tuna.NewSessionRequest).Customer.ID)
+		assert.True(t, token.AssertExpectations(t))
+	})
+
+	t.Run("should match expectations by request", func(t *testing.T) {
+		payment := NewPaymentAPI()
+		declined := errors.New("declined")
+		payment.OnInit().With(func(r tuna.InitRequest) bool { return r.PartnerUniqueID == "bad" }).Return(nil, declined)
+		payment.OnInit().Return(&tuna.InitResponse{PaymentKey: "key"}, nil)
+
+		_, err := payment.Init(tuna.InitRequest{PartnerUniqueID: "bad"})
+		assert.ErrorIs(t, err, declined)
+
+		res, err := payment.InitWithContext(context.Background(), tuna.InitRequest{PartnerUniqueID: "good"})
+		require.NoError(t, err)
+		assert.Equal(t, "key", res.PaymentKey)
+	})
+
+	t.Run("should run programmed functions", func(t *testing.T) {
+		payment := NewPaymentAPI()
+		payment.OnCapture().Do(func(ctx context.Context, r tuna.CaptureRequest) (*tuna.CaptureResponse, error) {
+			return &tuna.CaptureResponse{Message: tuna.Message{Info: fmt.Sprint(r.Amount)}}, nil
+		})
+
+		res, err := payment.Capture(tuna.CaptureRequest{Amount: 10})
+		require.NoError(t, err)
+		assert.Equal(t, "10", res.Message.Info)
+	})
+
+	t.Run("should fail unexpected and missing calls", func(t *testing.T) {
+		payment := NewPaymentAPI()
+		payment.OnStatus().Times(2)
+
+		_, err := payment.Cancel(tuna.CancelRequest{})
+		assert.ErrorIs(t, err, ErrUnexpectedCall)
+
+		_, err = payment.Status(tuna.StatusRequest{})
+		assert.NoError(t, err)
+
+		ft := &fakeT{}
+		assert.False(t, payment.AssertExpectations(ft))
+		assert.Equal(t, []string{"Status: expected 2 calls, got 1"}, ft.errors)
+	})
+}
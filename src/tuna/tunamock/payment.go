@@ -0,0 +1,124 @@
+// Code generated by tunamock/internal/gen. DO NOT EDIT.
+
+package tunamock
+
+import (
+	"context"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+// PaymentAPI is a programmable mock of tuna.PaymentAPI.
+type PaymentAPI struct {
+	mock
+}
+
+var _ tuna.PaymentAPI = (*PaymentAPI)(nil)
+
+func NewPaymentAPI() *PaymentAPI {
+	return &PaymentAPI{}
+}
+
+// OnCancel sets an expectation for Cancel and CancelWithContext.
+func (m *PaymentAPI) OnCancel() *Expectation[tuna.CancelRequest, tuna.CancelResponse] {
+	return expect[tuna.CancelRequest, tuna.CancelResponse](&m.mock, "Cancel")
+}
+
+func (m *PaymentAPI) Cancel(request tuna.CancelRequest) (*tuna.CancelResponse, error) {
+	return m.CancelWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) CancelWithContext(ctx context.Context, request tuna.CancelRequest) (*tuna.CancelResponse, error) {
+	return call[tuna.CancelRequest, tuna.CancelResponse](&m.mock, ctx, "Cancel", request)
+}
+
+// OnCancelItem sets an expectation for CancelItem and CancelItemWithContext.
+func (m *PaymentAPI) OnCancelItem() *Expectation[tuna.CancelItemRequest, tuna.CancelItemResponse] {
+	return expect[tuna.CancelItemRequest, tuna.CancelItemResponse](&m.mock, "CancelItem")
+}
+
+func (m *PaymentAPI) CancelItem(request tuna.CancelItemRequest) (*tuna.CancelItemResponse, error) {
+	return m.CancelItemWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) CancelItemWithContext(ctx context.Context, request tuna.CancelItemRequest) (*tuna.CancelItemResponse, error) {
+	return call[tuna.CancelItemRequest, tuna.CancelItemResponse](&m.mock, ctx, "CancelItem", request)
+}
+
+// OnCapture sets an expectation for Capture and CaptureWithContext.
+func (m *PaymentAPI) OnCapture() *Expectation[tuna.CaptureRequest, tuna.CaptureResponse] {
+	return expect[tuna.CaptureRequest, tuna.CaptureResponse](&m.mock, "Capture")
+}
+
+func (m *PaymentAPI) Capture(request tuna.CaptureRequest) (*tuna.CaptureResponse, error) {
+	return m.CaptureWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) CaptureWithContext(ctx context.Context, request tuna.CaptureRequest) (*tuna.CaptureResponse, error) {
+	return call[tuna.CaptureRequest, tuna.CaptureResponse](&m.mock, ctx, "Capture", request)
+}
+
+// OnContinue sets an expectation for Continue and ContinueWithContext.
+func (m *PaymentAPI) OnContinue() *Expectation[tuna.ContinueRequest, tuna.ContinueResponse] {
+	return expect[tuna.ContinueRequest, tuna.ContinueResponse](&m.mock, "Continue")
+}
+
+func (m *PaymentAPI) Continue(request tuna.ContinueRequest) (*tuna.ContinueResponse, error) {
+	return m.ContinueWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) ContinueWithContext(ctx context.Context, request tuna.ContinueRequest) (*tuna.ContinueResponse, error) {
+	return call[tuna.ContinueRequest, tuna.ContinueResponse](&m.mock, ctx, "Continue", request)
+}
+
+// OnFunction sets an expectation for Function and FunctionWithContext.
+func (m *PaymentAPI) OnFunction() *Expectation[tuna.FunctionRequest, tuna.FunctionResponse] {
+	return expect[tuna.FunctionRequest, tuna.FunctionResponse](&m.mock, "Function")
+}
+
+func (m *PaymentAPI) Function(request tuna.FunctionRequest) (*tuna.FunctionResponse, error) {
+	return m.FunctionWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) FunctionWithContext(ctx context.Context, request tuna.FunctionRequest) (*tuna.FunctionResponse, error) {
+	return call[tuna.FunctionRequest, tuna.FunctionResponse](&m.mock, ctx, "Function", request)
+}
+
+// OnInit sets an expectation for Init and InitWithContext.
+func (m *PaymentAPI) OnInit() *Expectation[tuna.InitRequest, tuna.InitResponse] {
+	return expect[tuna.InitRequest, tuna.InitResponse](&m.mock, "Init")
+}
+
+func (m *PaymentAPI) Init(request tuna.InitRequest) (*tuna.InitResponse, error) {
+	return m.InitWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) InitWithContext(ctx context.Context, request tuna.InitRequest) (*tuna.InitResponse, error) {
+	return call[tuna.InitRequest, tuna.InitResponse](&m.mock, ctx, "Init", request)
+}
+
+// OnOptions sets an expectation for Options and OptionsWithContext.
+func (m *PaymentAPI) OnOptions() *Expectation[tuna.OptionsRequest, tuna.OptionsResponse] {
+	return expect[tuna.OptionsRequest, tuna.OptionsResponse](&m.mock, "Options")
+}
+
+func (m *PaymentAPI) Options(request tuna.OptionsRequest) (*tuna.OptionsResponse, error) {
+	return m.OptionsWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) OptionsWithContext(ctx context.Context, request tuna.OptionsRequest) (*tuna.OptionsResponse, error) {
+	return call[tuna.OptionsRequest, tuna.OptionsResponse](&m.mock, ctx, "Options", request)
+}
+
+// OnStatus sets an expectation for Status and StatusWithContext.
+func (m *PaymentAPI) OnStatus() *Expectation[tuna.StatusRequest, tuna.StatusResponse] {
+	return expect[tuna.StatusRequest, tuna.StatusResponse](&m.mock, "Status")
+}
+
+func (m *PaymentAPI) Status(request tuna.StatusRequest) (*tuna.StatusResponse, error) {
+	return m.StatusWithContext(context.Background(), request)
+}
+
+func (m *PaymentAPI) StatusWithContext(ctx context.Context, request tuna.StatusRequest) (*tuna.StatusResponse, error) {
+	return call[tuna.StatusRequest, tuna.StatusResponse](&m.mock, ctx, "Status", request)
+}
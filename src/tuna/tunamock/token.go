@@ -0,0 +1,98 @@
+// Code generated by tunamock/internal/gen. DO NOT EDIT.
+
+package tunamock
+
+import (
+	"context"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+// TokenAPI is a programmable mock of tuna.TokenAPI.
+type TokenAPI struct {
+	mock
+}
+
+var _ tuna.TokenAPI = (*TokenAPI)(nil)
+
+func NewTokenAPI() *TokenAPI {
+	return &TokenAPI{}
+}
+
+// OnBindCVV sets an expectation for BindCVV and BindCVVWithContext.
+func (m *TokenAPI) OnBindCVV() *Expectation[tuna.BindCVVRequest, tuna.BindCVVResponse] {
+	return expect[tuna.BindCVVRequest, tuna.BindCVVResponse](&m.mock, "BindCVV")
+}
+
+func (m *TokenAPI) BindCVV(request tuna.BindCVVRequest) (*tuna.BindCVVResponse, error) {
+	return m.BindCVVWithContext(context.Background(), request)
+}
+
+func (m *TokenAPI) BindCVVWithContext(ctx context.Context, request tuna.BindCVVRequest) (*tuna.BindCVVResponse, error) {
+	return call[tuna.BindCVVRequest, tuna.BindCVVResponse](&m.mock, ctx, "BindCVV", request)
+}
+
+// OnDeleteCardToken sets an expectation for DeleteCardToken and DeleteCardTokenWithContext.
+func (m *TokenAPI) OnDeleteCardToken() *Expectation[tuna.DeleteCardTokenRequest, tuna.DeleteCardTokenResponse] {
+	return expect[tuna.DeleteCardTokenRequest, tuna.DeleteCardTokenResponse](&m.mock, "DeleteCardToken")
+}
+
+func (m *TokenAPI) DeleteCardToken(request tuna.DeleteCardTokenRequest) (*tuna.DeleteCardTokenResponse, error) {
+	return m.DeleteCardTokenWithContext(context.Background(), request)
+}
+
+func (m *TokenAPI) DeleteCardTokenWithContext(ctx context.Context, request tuna.DeleteCardTokenRequest) (*tuna.DeleteCardTokenResponse, error) {
+	return call[tuna.DeleteCardTokenRequest, tuna.DeleteCardTokenResponse](&m.mock, ctx, "DeleteCardToken", request)
+}
+
+// OnGenerateCardToken sets an expectation for GenerateCardToken and GenerateCardTokenWithContext.
+func (m *TokenAPI) OnGenerateCardToken() *Expectation[tuna.GenerateCardTokenRequest, tuna.GenerateCardTokenResponse] {
+	return expect[tuna.GenerateCardTokenRequest, tuna.GenerateCardTokenResponse](&m.mock, "GenerateCardToken")
+}
+
+func (m *TokenAPI) GenerateCardToken(request tuna.GenerateCardTokenRequest) (*tuna.GenerateCardTokenResponse, error) {
+	return m.GenerateCardTokenWithContext(context.Background(), request)
+}
+
+func (m *TokenAPI) GenerateCardTokenWithContext(ctx context.Context, request tuna.GenerateCardTokenRequest) (*tuna.GenerateCardTokenResponse, error) {
+	return call[tuna.GenerateCardTokenRequest, tuna.GenerateCardTokenResponse](&m.mock, ctx, "GenerateCardToken", request)
+}
+
+// OnListTokens sets an expectation for ListTokens and ListTokensWithContext.
+func (m *TokenAPI) OnListTokens() *Expectation[tuna.ListTokensRequest, tuna.ListTokensResponse] {
+	return expect[tuna.ListTokensRequest, tuna.ListTokensResponse](&m.mock, "ListTokens")
+}
+
+func (m *TokenAPI) ListTokens(request tuna.ListTokensRequest) (*tuna.ListTokensResponse, error) {
+	return m.ListTokensWithContext(context.Background(), request)
+}
+
+func (m *TokenAPI) ListTokensWithContext(ctx context.Context, request tuna.ListTokensRequest) (*tuna.ListTokensResponse, error) {
+	return call[tuna.ListTokensRequest, tuna.ListTokensResponse](&m.mock, ctx, "ListTokens", request)
+}
+
+// OnNewSession sets an expectation for NewSession and NewSessionWithContext.
+func (m *TokenAPI) OnNewSession() *Expectation[tuna.NewSessionRequest, tuna.NewSessionResponse] {
+	return expect[tuna.NewSessionRequest, tuna.NewSessionResponse](&m.mock, "NewSession")
+}
+
+func (m *TokenAPI) NewSession(request tuna.NewSessionRequest) (*tuna.NewSessionResponse, error) {
+	return m.NewSessionWithContext(context.Background(), request)
+}
+
+func (m *TokenAPI) NewSessionWithContext(ctx context.Context, request tuna.NewSessionRequest) (*tuna.NewSessionResponse, error) {
+	return call[tuna.NewSessionRequest, tuna.NewSessionResponse](&m.mock, ctx, "NewSession", request)
+}
+
+// OnValidateSession sets an expectation for ValidateSession and ValidateSessionWithContext.
+func (m *TokenAPI) OnValidateSession() *Expectation[tuna.ValidadeSessionRequest, tuna.ValidadeSessionResponse] {
+	return expect[tuna.ValidadeSessionRequest, tuna.ValidadeSessionResponse](&m.mock, "ValidateSession")
+}
+
+func (m *TokenAPI) ValidateSession(request tuna.ValidadeSessionRequest) (*tuna.ValidadeSessionResponse, error) {
+	return m.ValidateSessionWithContext(context.Background(), request)
+}
+
+func (m *TokenAPI) ValidateSessionWithContext(ctx context.Context, request tuna.ValidadeSessionRequest) (*tuna.ValidadeSessionResponse, error) {
+	return call[tuna.ValidadeSessionRequest, tuna.ValidadeSessionResponse](&m.mock, ctx, "ValidateSession", request)
+}
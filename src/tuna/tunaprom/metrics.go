@@ -0,0 +1,67 @@
+// Package tunaprom exports the metrics of Tuna clients to Prometheus.
+package tunaprom
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+// Metrics implements tuna.Metrics with Prometheus collectors.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	outcomes *prometheus.CounterVec
+}
+
+var _ tuna.Metrics = (*Metrics)(nil)
+
+// New creates the collectors under namespace and registers them with reg.
+func New(reg prometheus.Registerer, namespace string) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tuna_requests_total",
+			Help:      "Number of calls to the Tuna API.",
+		}, []string{"endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tuna_request_duration_seconds",
+			Help:      "Latency of calls to the Tuna API.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tuna_errors_total",
+			Help:      "Number of failed calls to the Tuna API.",
+		}, []string{"endpoint", "kind"}),
+		outcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tuna_payment_outcomes_total",
+			Help:      "Number of payment statuses reported by Tuna.",
+		}, []string{"endpoint", "outcome"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.latency, m.errors, m.outcomes} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) ObserveRequest(endpoint tuna.Endpoint, status string, duration time.Duration) {
+	m.requests.WithLabelValues(string(endpoint), status).Inc()
+	m.latency.WithLabelValues(string(endpoint), status).Observe(duration.Seconds())
+}
+
+func (m *Metrics) IncError(endpoint tuna.Endpoint, kind string) {
+	m.errors.WithLabelValues(string(endpoint), kind).Inc()
+}
+
+func (m *Metrics) IncPaymentOutcome(endpoint tuna.Endpoint, outcome string) {
+	m.outcomes.WithLabelValues(string(endpoint), outcome).Inc()
+}
@@ -0,0 +1,61 @@
+package tunaprom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rodrigodev/tuna_go/src/tuna"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("should export calls, errors and outcomes by label", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		m, err := New(reg, "shop")
+		require.NoError(t, err)
+
+		m.ObserveRequest(tuna.EndpointInit, "200", 10*time.Millisecond)
+		m.ObserveRequest(tuna.EndpointInit, "200", 20*time.Millisecond)
+		m.ObserveRequest(tuna.EndpointStatus, "none", time.Millisecond)
+		m.IncError(tuna.EndpointStatus, tuna.ErrorKindTransport)
+		m.IncPaymentOutcome(tuna.EndpointInit, tuna.OutcomeApproved)
+
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues(string(tuna.EndpointInit), "200")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(string(tuna.EndpointStatus), "none")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.errors.WithLabelValues(string(tuna.EndpointStatus), tuna.ErrorKindTransport)))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.outcomes.WithLabelValues(string(tuna.EndpointInit), tuna.OutcomeApproved)))
+
+		assert.Equal(t, 2, testutil.CollectAndCount(m.requests, "shop_tuna_requests_total"))
+		assert.Equal(t, 2, testutil.CollectAndCount(m.latency, "shop_tuna_request_duration_seconds"))
+		assert.Equal(t, 1, testutil.CollectAndCount(m.errors, "shop_tuna_errors_total"))
+		assert.Equal(t, 1, testutil.CollectAndCount(m.outcomes, "shop_tuna_payment_outcomes_total"))
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		labels := make(map[string][]string)
+		for _, f := range families {
+			for _, l := range f.GetMetric()[0].GetLabel() {
+				labels[f.GetName()] = append(labels[f.GetName()], l.GetName())
+			}
+		}
+		assert.Equal(t, map[string][]string{
+			"shop_tuna_requests_total":           {"endpoint", "status"},
+			"shop_tuna_request_duration_seconds": {"endpoint", "status"},
+			"shop_tuna_errors_total":             {"endpoint", "kind"},
+			"shop_tuna_payment_outcomes_total":   {"endpoint", "outcome"},
+		}, labels)
+	})
+
+	t.Run("should fail to register twice", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		_, err := New(reg, "shop")
+		require.NoError(t, err)
+
+		_, err = New(reg, "shop")
+		var are prometheus.AlreadyRegisteredError
+		assert.ErrorAs(t, err, &are)
+	})
+}
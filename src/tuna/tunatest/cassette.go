@@ -0,0 +1,262 @@
+package tunatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned in replay mode for a request that matches no
+// recorded interaction.
+var ErrNoInteraction = errors.New("tunatest: no recorded interaction matches the request")
+
+const redacted = "[REDACTED]"
+
+// Mode selects whether a Recorder talks to Tuna or replays a cassette.
+type Mode int
+
+const (
+	// ModeReplay answers from the cassette without any network access.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to Tuna and records them into the cassette.
+	ModeRecord
+)
+
+// Interaction is a recorded request and its response. Request and response
+// bodies are normalized and scrubbed of card numbers, CVVs and app tokens.
+type Interaction struct {
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	RequestBody  json.RawMessage `json:"requestBody,omitempty"`
+	StatusCode   int             `json:"statusCode"`
+	ResponseBody json.RawMessage `json:"responseBody,omitempty"`
+}
+
+// Cassette is the file format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records exchanges with Tuna into a
+// cassette file, or replays them from it. Requests are matched by method, path
+// and normalized body; identical requests are replayed in recording order.
+//
+//	rec, err := tunatest.NewRecorder("testdata/checkout.json", mode, nil)
+//	defer rec.Save()
+//	client, err := tuna.NewClient(&http.Client{Transport: rec}, conf)
+type Recorder struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder returns a recorder for the cassette at path. In replay mode the
+// cassette must exist. next sends requests in record mode and defaults to
+// http.DefaultTransport.
+func NewRecorder(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	r := &Recorder{path: path, mode: mode, next: next}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("tunatest: cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+
+	// Saving indents the bodies; matching compares them compacted.
+	for i, in := range r.cassette.Interactions {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, in.RequestBody); err == nil {
+			r.cassette.Interactions[i].RequestBody = buf.Bytes()
+		}
+	}
+
+	return r, nil
+}
+
+// Client returns an http.Client using the recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	normalized := scrubJSON(body)
+
+	if r.mode == ModeReplay {
+		return r.replay(req, normalized)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method:       req.Method,
+		Path:         req.URL.Path,
+		RequestBody:  normalized,
+		StatusCode:   resp.StatusCode,
+		ResponseBody: scrubJSON(respBody),
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, normalized json.RawMessage) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Method != req.Method || in.Path != req.URL.Path || !bytes.Equal(in.RequestBody, normalized) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s %s", ErrNoInteraction, req.Method, req.URL.Path, normalized)
+	}
+	r.used[match] = true
+
+	in := r.cassette.Interactions[match]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+		StatusCode:    in.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(in.ResponseBody)),
+		ContentLength: int64(len(in.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing
+// in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// scrubJSON returns body re-encoded with sorted keys and sensitive values
+// scrubbed. Bodies that are not JSON are returned as a JSON string.
+func scrubJSON(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		s, _ := json.Marshal(string(body))
+		return s
+	}
+
+	out, _ := json.Marshal(scrub(v))
+	return out
+}
+
+func scrub(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			switch strings.ToLower(k) {
+			case "cardnumber":
+				t[k] = maskPAN(val)
+			case "cvv", "apptoken":
+				t[k] = redacted
+			default:
+				t[k] = scrub(val)
+			}
+		}
+		return t
+	case []interface{}:
+		for i := range t {
+			t[i] = scrub(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func maskPAN(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if s, ok := v.(string); ok && strings.Contains(s, "*") {
+		return s
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, fmt.Sprint(v))
+	if len(digits) < 13 {
+		return redacted
+	}
+	return digits[:6] + strings.Repeat("*", len(digits)-10) + digits[len(digits)-4:]
+}
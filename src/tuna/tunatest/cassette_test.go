@@ -0,0 +1,72 @@
+package tunatest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	init := tuna.InitRequest{
+		PartnerUniqueID: "order-1",
+		PaymentData: tuna.PaymentData{PaymentMethods: []tuna.PaymentMethods{{
+			Amount:   100,
+			CardInfo: tuna.CardInfo{CardNumber: "4111111111111111"},
+		}}},
+	}
+
+	s := NewServer()
+	rec, err := NewRecorder(path, ModeRecord, s.Client().Transport)
+	require.NoError(t, err)
+
+	client, err := tuna.NewClient(rec.Client(), s.Config())
+	require.NoError(t, err)
+
+	recorded, err := client.Payment().Init(init)
+	require.NoError(t, err)
+	_, err = client.Payment().Capture(tuna.CaptureRequest{PartnerUniqueID: "order-1", Amount: 100, AppToken: "secret-app-token"})
+	require.NoError(t, err)
+	require.NoError(t, rec.Save())
+	s.Close()
+
+	t.Run("should scrub sensitive data from the cassette", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "4111111111111111")
+		assert.Contains(t, string(data), "411111******1111")
+		assert.NotContains(t, string(data), "secret-app-token")
+		assert.NotContains(t, string(data), AppToken)
+	})
+
+	t.Run("should replay without network access", func(t *testing.T) {
+		rec, err := NewRecorder(path, ModeReplay, nil)
+		require.NoError(t, err)
+
+		client, err := tuna.NewClient(rec.Client(), s.Config())
+		require.NoError(t, err)
+
+		replayed, err := client.Payment().Init(init)
+		require.NoError(t, err)
+		assert.Equal(t, recorded, replayed)
+
+		_, err = client.Payment().Capture(tuna.CaptureRequest{PartnerUniqueID: "order-1", Amount: 100, AppToken: "other-token"})
+		require.NoError(t, err)
+	})
+
+	t.Run("should fail for an unknown request", func(t *testing.T) {
+		rec, err := NewRecorder(path, ModeReplay, nil)
+		require.NoError(t, err)
+
+		client, err := tuna.NewClient(rec.Client(), s.Config())
+		require.NoError(t, err)
+
+		_, err = client.Payment().Init(tuna.InitRequest{PartnerUniqueID: "order-2"})
+		assert.ErrorIs(t, err, ErrNoInteraction)
+	})
+}
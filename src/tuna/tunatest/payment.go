@@ -0,0 +1,225 @@
+package tunatest
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+func (s *Server) init(req tuna.InitRequest) tuna.InitResponse {
+	if p, ok := s.payments[req.PartnerUniqueID]; ok {
+		return s.initResponse(req.PartnerUniqueID, p)
+	}
+
+	outcome, ok := s.outcomes[req.PartnerUniqueID]
+	if !ok {
+		outcome = s.outcome
+	}
+
+	status, methodMessage := StatusAuthorized, success("Authorized")
+	switch outcome {
+	case Decline:
+		status, methodMessage = StatusDenied, failure(CodeDeclined, "Not authorized")
+	case Pending, Redirect3DS:
+		status, methodMessage = StatusPending, success("Waiting for customer")
+	}
+
+	p := &payment{
+		key:       s.nextID("pay"),
+		status:    status,
+		outcome:   outcome,
+		items:     req.PaymentItems,
+		cancelled: make([]int, len(req.PaymentItems)),
+		date:      time.Now().UTC(),
+	}
+	for i, m := range req.PaymentData.PaymentMethods {
+		p.amount += m.Amount
+		p.amounts = append(p.amounts, m.Amount)
+		p.methods = append(p.methods, tuna.Method{
+			Message:    methodMessage,
+			MethodType: m.PaymentMethodType,
+			Status:     status,
+			MethodId:   i,
+		})
+	}
+	if outcome == Redirect3DS {
+		p.redirect = s.URL + "/3ds/" + p.key
+	}
+	s.payments[req.PartnerUniqueID] = p
+
+	return s.initResponse(req.PartnerUniqueID, p)
+}
+
+func (s *Server) initResponse(partnerUniqueID string, p *payment) tuna.InitResponse {
+	return tuna.InitResponse{
+		Status:          p.status,
+		Methods:         p.methods,
+		PaymentKey:      p.key,
+		PartnerUniqueId: partnerUniqueID,
+		PaymentDate:     tuna.Date{Time: p.date},
+		Message:         success("Payment initialized"),
+		RedirectInfo:    tuna.RedirectInfo{Url: p.redirect},
+	}
+}
+
+func (s *Server) setStatus(p *payment, status tuna.PaymentStatus) {
+	p.status = status
+	for i := range p.methods {
+		p.methods[i].Status = status
+	}
+}
+
+func (s *Server) cancel(req tuna.CancelRequest) tuna.CancelResponse {
+	p, ok := s.payments[req.PartnerUniqueID]
+	if !ok {
+		return tuna.CancelResponse{Message: failure(CodeFailed, "Payment not found")}
+	}
+	if p.status == StatusDenied || p.status == StatusCancelled {
+		return tuna.CancelResponse{Status: p.status, Methods: p.methods, Message: failure(CodeFailed, "Payment cannot be cancelled")}
+	}
+
+	status := StatusCancelled
+	if p.status == StatusCaptured {
+		status = StatusRefunded
+	}
+	s.setStatus(p, status)
+
+	return tuna.CancelResponse{Status: p.status, Methods: p.methods, Message: success("Payment cancelled")}
+}
+
+func (s *Server) cancelItem(req tuna.CancelItemRequest) tuna.CancelItemResponse {
+	p, ok := s.payments[req.PartnerUniqueID]
+	if !ok {
+		return tuna.CancelItemResponse{Message: failure(CodeFailed, "Payment not found")}
+	}
+
+	// Items are identified by their index in the Init request, as in status.
+	items := make([]tuna.Item, 0, len(req.ItemsDetail))
+	for _, detail := range req.ItemsDetail {
+		item := tuna.Item{PartnerUniqueId: detail.DetailUniqueID}
+		i, err := strconv.Atoi(detail.DetailUniqueID)
+		switch {
+		case err != nil || i < 0 || i >= len(p.items):
+			item.Status, item.Message = p.status, failure(CodeFailed, "Item not found")
+		case detail.ItemQuantity <= 0 || p.cancelled[i]+detail.ItemQuantity > p.items[i].ItemQuantity:
+			item.Status, item.Message = p.status, failure(CodeFailed, "Quantity exceeds the item quantity")
+		default:
+			p.cancelled[i] += detail.ItemQuantity
+			item.Status, item.Message = StatusCancelled, success("Item cancelled")
+		}
+		items = append(items, item)
+	}
+
+	return tuna.CancelItemResponse{Status: p.status, Items: items, Message: success("Items cancelled")}
+}
+
+func (s *Server) capture(req tuna.CaptureRequest) tuna.CaptureResponse {
+	p, ok := s.payments[req.PartnerUniqueID]
+	if !ok {
+		return tuna.CaptureResponse{Message: failure(CodeFailed, "Payment not found")}
+	}
+	if p.status != StatusAuthorized && p.status != StatusCaptured {
+		return tuna.CaptureResponse{Status: p.status, Methods: p.methods, Message: failure(CodeFailed, "Payment is not authorized")}
+	}
+	if req.Amount > p.amount-p.cancelledAmount()-p.captured {
+		return tuna.CaptureResponse{Status: p.status, Methods: p.methods, Message: failure(CodeFailed, "Amount exceeds the authorized amount")}
+	}
+
+	p.captured += req.Amount
+	s.setStatus(p, StatusCaptured)
+
+	return tuna.CaptureResponse{Status: p.status, Methods: p.methods, Message: success("Payment captured")}
+}
+
+func (p *payment) cancelledAmount() int {
+	var amount int
+	for i, quantity := range p.cancelled {
+		amount += p.items[i].Amount * quantity
+	}
+	return amount
+}
+
+func (s *Server) continuePayment(req tuna.ContinueRequest) tuna.ContinueResponse {
+	p, ok := s.payments[req.PartnerUniqueID]
+	if !ok {
+		return tuna.ContinueResponse{Message: failure(CodeFailed, "Payment not found")}
+	}
+	if p.status != StatusPending {
+		return tuna.ContinueResponse{Message: failure(CodeFailed, "Payment is not pending")}
+	}
+
+	s.setStatus(p, StatusAuthorized)
+	p.redirect = ""
+
+	return tuna.ContinueResponse{Message: success("Payment continued")}
+}
+
+func (s *Server) status(req tuna.StatusRequest) tuna.StatusResponse {
+	p, ok := s.payments[req.PartnerUniqueID]
+	if !ok {
+		return tuna.StatusResponse{Message: failure(CodeFailed, "Payment not found")}
+	}
+
+	res := tuna.StatusResponse{
+		Status:          p.status,
+		PaymentKey:      p.key,
+		PartnerUniqueID: req.PartnerUniqueID,
+		PaymentDate:     tuna.Date{Time: p.date},
+		Amount:          p.amount,
+		Message:         success("Payment found"),
+	}
+
+	// Captured amounts are spread over the methods in order.
+	captured := p.captured
+	for i, m := range p.methods {
+		ms := tuna.PaymentMethodStatus{
+			MethodID:   m.MethodId,
+			MethodType: m.MethodType,
+			Status:     m.Status,
+			Amount:     p.amounts[i],
+			Message:    m.Message,
+		}
+		if ms.Status != tuna.PaymentStatusDenied && ms.Status != tuna.PaymentStatusPending {
+			ms.AuthorizationCode = fmt.Sprintf("%06d", i+1)
+			ms.NSU = p.key
+			ms.AuthorizationDate = tuna.Date{Time: p.date}
+		}
+		ms.CapturedAmount = min(captured, ms.Amount)
+		captured -= ms.CapturedAmount
+		if ms.Status == tuna.PaymentStatusRefunded {
+			ms.RefundedAmount = ms.CapturedAmount
+			ms.Refunds = []tuna.Refund{{Amount: ms.RefundedAmount, NSU: p.key, Status: tuna.PaymentStatusRefunded}}
+		}
+		res.Methods = append(res.Methods, ms)
+	}
+
+	for i, item := range p.items {
+		is := tuna.PaymentItemStatus{
+			DetailUniqueID:     fmt.Sprint(i),
+			ProductDescription: item.ProductDescription,
+			Amount:             item.Amount,
+			ItemQuantity:       item.ItemQuantity,
+			CancelledQuantity:  p.cancelled[i],
+			Status:             res.Status,
+		}
+		if is.ItemQuantity > 0 && is.CancelledQuantity == is.ItemQuantity {
+			is.Status = tuna.PaymentStatusCancelled
+		}
+		res.Items = append(res.Items, is)
+	}
+
+	return res
+}
+
+func (s *Server) options(req tuna.OptionsRequest) tuna.OptionsResponse {
+	return tuna.OptionsResponse{PaymentOptions: s.paymentOptions, Message: success("Options listed")}
+}
+
+func (s *Server) function(req tuna.FunctionRequest) tuna.FunctionResponse {
+	return tuna.FunctionResponse{
+		Response: map[string]interface{}{"function": req.FunctionName, "balance": 0},
+		Message:  success("Function executed"),
+	}
+}
@@ -0,0 +1,344 @@
+// Package tunatest provides an in-memory fake of the Tuna Token and Payment
+// APIs for tests.
+package tunatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+// AppToken is the app token the fake server accepts unless changed with
+// SetAppToken.
+const AppToken = "tunatest-app-token"
+
+// Outcome scripts how the fake server answers an Init call.
+type Outcome int
+
+const (
+	// Approve authorizes the payment.
+	Approve Outcome = iota
+	// Decline denies the payment.
+	Decline
+	// Pending leaves the payment waiting for the customer, e.g. a PIX or
+	// boleto payment, until Continue is called.
+	Pending
+	// Redirect3DS leaves the payment pending with a 3DS redirect URL.
+	Redirect3DS
+)
+
+// Payment status codes returned by the fake server.
+const (
+	StatusStarted    = tuna.PaymentStatusStarted
+	StatusAuthorized = tuna.PaymentStatusAuthorized
+	StatusCaptured   = tuna.PaymentStatusCaptured
+	StatusRefunded   = tuna.PaymentStatusRefunded
+	StatusDenied     = tuna.PaymentStatusDenied
+	StatusCancelled  = tuna.PaymentStatusCancelled
+	StatusPending    = tuna.PaymentStatusPending
+)
+
+// Codes of the token API and of Message blocks returned by the fake server.
+const (
+	CodeSuccess        = 1
+	CodeInvalidSession = -1
+	CodeInvalidToken   = -2
+	CodeDeclined       = -3
+	CodeFailed         = -99
+)
+
+var paths = map[string]tuna.Endpoint{
+	"/api/Token/NewSession":      tuna.EndpointNewSession,
+	"/api/Token/ValidateSession": tuna.EndpointValidateSession,
+	"/api/Token/Generate":        tuna.EndpointGenerateCardToken,
+	"/api/Token/List":            tuna.EndpointListTokens,
+	"/api/Token/Delete":          tuna.EndpointDeleteCardToken,
+	"/api/Token/Bind":            tuna.EndpointBindCVV,
+	"/api/Payment/Init":          tuna.EndpointInit,
+	"/api/Payment/Cancel":        tuna.EndpointCancel,
+	"/api/Payment/CancelItem":    tuna.EndpointCancelItem,
+	"/api/Payment/Capture":       tuna.EndpointCapture,
+	"/api/Payment/Continue":      tuna.EndpointContinue,
+	"/api/Payment/Status":        tuna.EndpointStatus,
+	"/api/Payment/Options":       tuna.EndpointOptions,
+	"/api/Payment/Function":      tuna.EndpointFunction,
+}
+
+// Request is a request received by the fake server.
+type Request struct {
+	Endpoint tuna.Endpoint
+	Header   http.Header
+	Body     []byte
+}
+
+// Decode decodes the JSON body of the request into v.
+func (r Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+type session struct {
+	customer tuna.Customer
+	created  time.Time
+	tokens   map[string]tuna.TokenData
+	order    []string
+}
+
+type payment struct {
+	key      string
+	status   tuna.PaymentStatus
+	amount   int
+	captured int
+	outcome  Outcome
+	methods  []tuna.Method
+	amounts  []int
+	items    []tuna.PaymentItem
+	date     time.Time
+	redirect string
+	// cancelled is the cancelled quantity of each item.
+	cancelled []int
+}
+
+// Server is a fake Tuna server backed by httptest.Server. Sessions, card
+// tokens and payments live in memory for the lifetime of the server.
+type Server struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	appToken       string
+	outcome        Outcome
+	outcomes       map[string]Outcome
+	paymentOptions []tuna.PaymentOption
+	sessions       map[string]*session
+	payments       map[string]*payment
+	requests       []Request
+	seq            int
+}
+
+// NewServer starts a fake server. Callers must Close it.
+func NewServer() *Server {
+	s := &Server{
+		appToken: AppToken,
+		outcomes: make(map[string]Outcome),
+		sessions: make(map[string]*session),
+		payments: make(map[string]*payment),
+		paymentOptions: []tuna.PaymentOption{{
+			Name:            "CreditCard",
+			DisplayName:     "Credit card",
+			AcceptedBrands:  []string{"VISA", "MASTERCARD"},
+			PaymentBehavior: tuna.PaymentBehaviorDirect,
+		}},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Config returns a client config pointing both APIs at the fake server.
+func (s *Server) Config() tuna.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return tuna.Config{BaseURL: s.URL, AppToken: s.appToken}
+}
+
+// SetAppToken changes the app token the server accepts.
+func (s *Server) SetAppToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.appToken = token
+}
+
+// SetOutcome sets how Init calls are answered by default.
+func (s *Server) SetOutcome(o Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outcome = o
+}
+
+// SetOutcomeFor sets how the Init call of partnerUniqueID is answered.
+func (s *Server) SetOutcomeFor(partnerUniqueID string, o Outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outcomes[partnerUniqueID] = o
+}
+
+// SetPaymentOptions sets the options returned by the Options endpoint.
+func (s *Server) SetPaymentOptions(options []tuna.PaymentOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paymentOptions = options
+}
+
+// Requests returns every request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Calls returns the number of requests received for endpoint.
+func (s *Server) Calls(endpoint tuna.Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, r := range s.requests {
+		if r.Endpoint == endpoint {
+			n++
+		}
+	}
+	return n
+}
+
+// LastRequest returns the last request received for endpoint.
+func (s *Server) LastRequest(endpoint tuna.Endpoint) (Request, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].Endpoint == endpoint {
+			return s.requests[i], true
+		}
+	}
+	return Request{}, false
+}
+
+// PaymentStatus returns the status of the payment of partnerUniqueID.
+func (s *Server) PaymentStatus(partnerUniqueID string) (tuna.PaymentStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[partnerUniqueID]
+	if !ok {
+		return "", false
+	}
+	return p.status, true
+}
+
+// Sessions returns the number of sessions created so far.
+func (s *Server) Sessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := paths[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{Endpoint: endpoint, Header: r.Header.Clone(), Body: body})
+
+	if r.Header.Get("x-tuna-apptoken") != s.appToken {
+		writeJSON(w, http.StatusUnauthorized, problem("Invalid app token.", nil))
+		return
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		writeJSON(w, http.StatusBadRequest, problem("One or more validation errors occurred.", map[string][]string{
+			"": {"A non-empty request body is required."},
+		}))
+		return
+	}
+
+	var res interface{}
+	switch endpoint {
+	case tuna.EndpointNewSession:
+		res, err = decodeAnd(body, s.newSession)
+	case tuna.EndpointValidateSession:
+		res, err = decodeAnd(body, s.validateSession)
+	case tuna.EndpointGenerateCardToken:
+		res, err = decodeAnd(body, s.generateCardToken)
+	case tuna.EndpointListTokens:
+		res, err = decodeAnd(body, s.listTokens)
+	case tuna.EndpointDeleteCardToken:
+		res, err = decodeAnd(body, s.deleteCardToken)
+	case tuna.EndpointBindCVV:
+		res, err = decodeAnd(body, s.bindCVV)
+	case tuna.EndpointInit:
+		res, err = decodeAnd(body, s.init)
+	case tuna.EndpointCancel:
+		res, err = decodeAnd(body, s.cancel)
+	case tuna.EndpointCancelItem:
+		res, err = decodeAnd(body, s.cancelItem)
+	case tuna.EndpointCapture:
+		res, err = decodeAnd(body, s.capture)
+	case tuna.EndpointContinue:
+		res, err = decodeAnd(body, s.continuePayment)
+	case tuna.EndpointStatus:
+		res, err = decodeAnd(body, s.status)
+	case tuna.EndpointOptions:
+		res, err = decodeAnd(body, s.options)
+	case tuna.EndpointFunction:
+		res, err = decodeAnd(body, s.function)
+	}
+
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, problem("One or more validation errors occurred.", map[string][]string{
+			"": {err.Error()},
+		}))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// decodeAnd decodes body into the request type of handler and calls it.
+func decodeAnd[Req any, Res any](body []byte, handler func(Req) Res) (interface{}, error) {
+	var req Req
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	return handler(req), nil
+}
+
+func problem(title string, errs map[string][]string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "https://tools.ietf.org/html/rfc7231#section-6.5.1",
+		"title":   title,
+		"traceId": "|tunatest.",
+		"errors":  errs,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s_%d", prefix, s.seq)
+}
+
+func success(text string) tuna.Message {
+	return tuna.Message{Code: CodeSuccess, Message: text}
+}
+
+func failure(code int, text string) tuna.Message {
+	return tuna.Message{Code: code, Message: text}
+}
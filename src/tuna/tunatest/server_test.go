@@ -0,0 +1,145 @@
+package tunatest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, s *Server) *tuna.Client {
+	conf := s.Config()
+	conf.Strict = true
+
+	client, err := tuna.NewClient(s.Client(), conf)
+	require.NoError(t, err)
+	return client
+}
+
+func TestServer_Token(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	client := newClient(t, s)
+
+	t.Run("should keep tokens per session", func(t *testing.T) {
+		session, err := client.Token().NewSession(tuna.NewSessionRequest{Customer: tuna.Customer{ID: "1"}})
+		require.NoError(t, err)
+
+		generated, err := client.Token().GenerateCardToken(tuna.GenerateCardTokenRequest{
+			SessionID: session.SessionID,
+			Card:      tuna.CardData{CardHolderName: "John"},
+		})
+		require.NoError(t, err)
+
+		_, err = client.Token().BindCVV(tuna.BindCVVRequest{Token: generated.Token, SessionID: session.SessionID, CVV: "123"})
+		require.NoError(t, err)
+
+		list, err := client.Token().ListTokens(tuna.ListTokensRequest{SessionID: session.SessionID})
+		require.NoError(t, err)
+		assert.Len(t, list.Tokens, 1)
+
+		_, err = client.Token().DeleteCardToken(tuna.DeleteCardTokenRequest{Token: generated.Token, SessionID: session.SessionID})
+		require.NoError(t, err)
+
+		_, err = client.Token().BindCVV(tuna.BindCVVRequest{Token: generated.Token, SessionID: session.SessionID})
+		assert.ErrorIs(t, err, tuna.ErrInvalidToken)
+	})
+
+	t.Run("should reject unknown sessions", func(t *testing.T) {
+		_, err := client.Token().ListTokens(tuna.ListTokensRequest{SessionID: "unknown"})
+		assert.ErrorIs(t, err, tuna.ErrInvalidSession)
+	})
+
+	t.Run("should reject a wrong app token", func(t *testing.T) {
+		conf := s.Config()
+		conf.AppToken = "wrong"
+		client, err := tuna.NewTokenClient(s.Client(), conf)
+		require.NoError(t, err)
+
+		_, err = client.NewSession(tuna.NewSessionRequest{})
+		var apiErr *tuna.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	})
+}
+
+func TestServer_Payment(t *testing.T) {
+	initRequest := func(partnerUniqueID string) tuna.InitRequest {
+		return tuna.InitRequest{
+			PartnerUniqueID: partnerUniqueID,
+			PaymentData: tuna.PaymentData{PaymentMethods: []tuna.PaymentMethods{{
+				PaymentMethodType: "1",
+				Amount:            100,
+			}}},
+		}
+	}
+
+	t.Run("should authorize and capture a payment", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		client := newClient(t, s)
+
+		res, err := client.Payment().Init(initRequest("order-1"))
+		require.NoError(t, err)
+		assert.Equal(t, StatusAuthorized, res.Status)
+
+		_, err = client.Payment().Capture(tuna.CaptureRequest{PartnerUniqueID: "order-1", Amount: 100})
+		require.NoError(t, err)
+
+		status, _ := s.PaymentStatus("order-1")
+		assert.Equal(t, StatusCaptured, status)
+		assert.Equal(t, 1, s.Calls(tuna.EndpointCapture))
+
+		st, err := client.Payment().Status(tuna.StatusRequest{PartnerUniqueID: "order-1"})
+		require.NoError(t, err)
+		assert.Equal(t, tuna.PaymentStatusCaptured, st.Status)
+		require.Len(t, st.Methods, 1)
+		assert.Equal(t, 100, st.Methods[0].CapturedAmount)
+		assert.NotEmpty(t, st.Methods[0].AuthorizationCode)
+
+		req, ok := s.LastRequest(tuna.EndpointCapture)
+		require.True(t, ok)
+		var capture tuna.CaptureRequest
+		require.NoError(t, req.Decode(&capture))
+		assert.Equal(t, 100, capture.Amount)
+	})
+
+	t.Run("should follow scripted outcomes", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		client := newClient(t, s)
+		s.SetOutcomeFor("declined", Decline)
+		s.SetOutcomeFor("3ds", Redirect3DS)
+
+		_, err := client.Payment().Init(initRequest("declined"))
+		assert.ErrorIs(t, err, tuna.ErrDeclined)
+
+		res, err := client.Payment().Init(initRequest("3ds"))
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, res.Status)
+		assert.NotEmpty(t, res.RedirectInfo.Url)
+
+		_, err = client.Payment().Continue(tuna.ContinueRequest{PartnerUniqueID: "3ds"})
+		require.NoError(t, err)
+
+		status, _ := s.PaymentStatus("3ds")
+		assert.Equal(t, StatusAuthorized, status)
+	})
+
+	t.Run("should not capture a cancelled payment", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		client := newClient(t, s)
+
+		_, err := client.Payment().Init(initRequest("order-1"))
+		require.NoError(t, err)
+		_, err = client.Payment().Cancel(tuna.CancelRequest{PartnerUniqueID: "order-1", CancelAll: true})
+		require.NoError(t, err)
+
+		_, err = client.Payment().Capture(tuna.CaptureRequest{PartnerUniqueID: "order-1", Amount: 100})
+		assert.ErrorIs(t, err, tuna.ErrRequestFailed)
+	})
+}
@@ -0,0 +1,93 @@
+package tunatest
+
+import (
+	"time"
+
+	"github.com/rodrigodev/tuna_go/src/tuna"
+)
+
+func (s *Server) newSession(req tuna.NewSessionRequest) tuna.NewSessionResponse {
+	id := s.nextID("session")
+	s.sessions[id] = &session{
+		customer: req.Customer,
+		created:  time.Now(),
+		tokens:   make(map[string]tuna.TokenData),
+	}
+
+	return tuna.NewSessionResponse{SessionID: id, Code: CodeSuccess, Message: "Session created"}
+}
+
+func (s *Server) validateSession(req tuna.ValidadeSessionRequest) tuna.ValidadeSessionResponse {
+	sess, ok := s.sessions[req.SessionID]
+	if !ok {
+		return tuna.ValidadeSessionResponse{}
+	}
+
+	return tuna.ValidadeSessionResponse{
+		SessionID:    req.SessionID,
+		CreationDate: sess.created,
+		Customer:     sess.customer,
+	}
+}
+
+func (s *Server) generateCardToken(req tuna.GenerateCardTokenRequest) tuna.GenerateCardTokenResponse {
+	sess, ok := s.sessions[req.SessionID]
+	if !ok {
+		return tuna.GenerateCardTokenResponse{Code: CodeInvalidSession, Message: "Session object is invalid"}
+	}
+
+	token := s.nextID("tok")
+	sess.tokens[token] = tuna.TokenData{
+		Token:           token,
+		Brand:           "VISA",
+		CardHolderName:  req.Card.CardHolderName,
+		ExpirationMonth: int(req.Card.ExpirationMonth),
+		ExpirationYear:  int(req.Card.ExpirationYear),
+		MaskedNumber:    "411111******1111",
+	}
+	sess.order = append(sess.order, token)
+
+	return tuna.GenerateCardTokenResponse{Token: token, CardBrand: "VISA", Code: CodeSuccess, Message: "Token generated"}
+}
+
+func (s *Server) listTokens(req tuna.ListTokensRequest) tuna.ListTokensResponse {
+	sess, ok := s.sessions[req.SessionID]
+	if !ok {
+		return tuna.ListTokensResponse{Code: CodeInvalidSession, Message: "Session object is invalid"}
+	}
+
+	tokens := []tuna.TokenData{}
+	for _, token := range sess.order {
+		if data, ok := sess.tokens[token]; ok {
+			tokens = append(tokens, data)
+		}
+	}
+
+	return tuna.ListTokensResponse{Tokens: tokens, Code: CodeSuccess, Message: "Tokens listed"}
+}
+
+func (s *Server) deleteCardToken(req tuna.DeleteCardTokenRequest) tuna.DeleteCardTokenResponse {
+	sess, ok := s.sessions[req.SessionID]
+	if !ok {
+		return tuna.DeleteCardTokenResponse{Code: CodeInvalidSession, Message: "Session object is invalid"}
+	}
+	if _, ok := sess.tokens[req.Token]; !ok {
+		return tuna.DeleteCardTokenResponse{Code: CodeInvalidToken, Message: "Token is invalid"}
+	}
+
+	delete(sess.tokens, req.Token)
+
+	return tuna.DeleteCardTokenResponse{Status: tuna.DeleteStatusDeleted, Code: CodeSuccess, Message: "Token deleted"}
+}
+
+func (s *Server) bindCVV(req tuna.BindCVVRequest) tuna.BindCVVResponse {
+	sess, ok := s.sessions[req.SessionID]
+	if !ok {
+		return tuna.BindCVVResponse{Code: CodeInvalidSession, Message: "Session object is invalid"}
+	}
+	if _, ok := sess.tokens[req.Token]; !ok {
+		return tuna.BindCVVResponse{Code: CodeInvalidToken, Message: "Token is invalid"}
+	}
+
+	return tuna.BindCVVResponse{Code: CodeSuccess, Message: "Bind succeeded"}
+}
@@ -0,0 +1,162 @@
+package tuna
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultWaitInterval    = time.Second
+	defaultWaitMaxInterval = 30 * time.Second
+	defaultWaitTimeout     = 10 * time.Minute
+)
+
+// ErrWaitTimeout is matched by the *WaitTimeoutError returned when a payment
+// does not reach a final status in time.
+var ErrWaitTimeout = errors.New("tuna: payment did not reach a final status in time")
+
+// WaitTimeoutError is returned by WaitForFinalStatus when its deadline passes.
+type WaitTimeoutError struct {
+	// Last is the last status seen, nil if no poll succeeded.
+	Last *StatusResponse
+}
+
+func (e *WaitTimeoutError) Error() string {
+	if e.Last == nil {
+		return ErrWaitTimeout.Error()
+	}
+	return fmt.Sprintf("%s: last status %s", ErrWaitTimeout, e.Last.Status)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return ErrWaitTimeout
+}
+
+// StatusTransition is a change of status seen while waiting. From is empty on
+// the first poll.
+type StatusTransition struct {
+	From     PaymentStatus
+	To       PaymentStatus
+	Response *StatusResponse
+}
+
+// WaitOptions configures WaitForFinalStatus. Zero fields fall back to their
+// defaults.
+type WaitOptions struct {
+	// Interval is the delay before the second poll. It doubles on every
+	// following poll up to MaxInterval. Defaults to 1s.
+	Interval time.Duration
+	// MaxInterval defaults to 30s.
+	MaxInterval time.Duration
+	// Jitter randomizes each delay by up to the given fraction, e.g. 0.2 for ±20%.
+	Jitter float64
+	// Timeout bounds the whole wait. Defaults to 10 minutes; a deadline on the
+	// context applies too.
+	Timeout time.Duration
+	// Done decides whether a status ends the wait. Defaults to
+	// PaymentStatus.IsFinal.
+	Done func(PaymentStatus) bool
+	// OnTransition, if set, is called on every change of status.
+	OnTransition func(StatusTransition)
+	// Transitions, if set, receives every change of status. Sends block until
+	// they are received or the wait ends; the channel is never closed.
+	Transitions chan<- StatusTransition
+}
+
+func (o *WaitOptions) delay(poll int) time.Duration {
+	initial := o.Interval
+	if initial <= 0 {
+		initial = defaultWaitInterval
+	}
+	max := o.MaxInterval
+	if max <= 0 {
+		max = defaultWaitMaxInterval
+	}
+
+	delay := initial << uint(poll-1)
+	if delay > max || delay <= 0 {
+		delay = max
+	}
+
+	if o.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * o.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// WaitForFinalStatus polls the status of the payment in request until it is
+// final, and returns that status. It returns a *WaitTimeoutError when
+// opts.Timeout passes first, the context error when ctx ends first, and any
+// error of the Status call, including a failed message, as is.
+func WaitForFinalStatus(ctx context.Context, api PaymentAPI, request StatusRequest, opts WaitOptions) (*StatusResponse, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
+	}
+	done := opts.Done
+	if done == nil {
+		done = PaymentStatus.IsFinal
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var last *StatusResponse
+	for poll := 1; ; poll++ {
+		res, err := api.StatusWithContext(waitCtx, request)
+		if err != nil {
+			if waitCtx.Err() != nil {
+				return last, waitError(ctx, last)
+			}
+			return last, err
+		}
+		if err := checkBusinessError(EndpointStatus, res); err != nil {
+			return last, err
+		}
+
+		if last == nil || res.Status != last.Status {
+			transition := StatusTransition{To: res.Status, Response: res}
+			if last != nil {
+				transition.From = last.Status
+			}
+			if opts.OnTransition != nil {
+				opts.OnTransition(transition)
+			}
+			if opts.Transitions != nil {
+				select {
+				case opts.Transitions <- transition:
+				case <-waitCtx.Done():
+					return res, waitError(ctx, res)
+				}
+			}
+		}
+		last = res
+
+		if done(res.Status) {
+			return res, nil
+		}
+
+		if err := sleep(waitCtx, opts.delay(poll)); err != nil {
+			return last, waitError(ctx, last)
+		}
+	}
+}
+
+// waitError tells a cancelled or expired parent context apart from the wait
+// timeout.
+func waitError(ctx context.Context, last *StatusResponse) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return &WaitTimeoutError{Last: last}
+}
+
+// WaitForFinalStatus polls the status of a payment until it is final. See
+// the package-level WaitForFinalStatus.
+func (s *PaymentAdapter) WaitForFinalStatus(ctx context.Context, request StatusRequest, opts WaitOptions) (*StatusResponse, error) {
+	return WaitForFinalStatus(ctx, s.paymentAPI, request, opts)
+}
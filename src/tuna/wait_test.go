@@ -0,0 +1,96 @@
+package tuna
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statusClient(statuses ...PaymentStatus) (*http.Client, *int) {
+	var polls int
+	return NewTestClient(func(req *http.Request) *http.Response {
+		status := statuses[len(statuses)-1]
+		if polls < len(statuses) {
+			status = statuses[polls]
+		}
+		polls++
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"status": "%s", "message": {"code": 1}}`, string(status)))),
+			Header:     make(http.Header),
+		}
+	}), &polls
+}
+
+func TestWaitForFinalStatus(t *testing.T) {
+	ctx := context.Background()
+	opts := WaitOptions{Interval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+	t.Run("should poll until the status is final", func(t *testing.T) {
+		client, polls := statusClient(PaymentStatusPending, PaymentStatusPending, PaymentStatusAuthorized)
+		api := newTestPaymentClient(t, client, Config{})
+
+		var transitions []StatusTransition
+		ch := make(chan StatusTransition, 10)
+		o := opts
+		o.OnTransition = func(tr StatusTransition) { transitions = append(transitions, tr) }
+		o.Transitions = ch
+
+		res, err := WaitForFinalStatus(ctx, api, StatusRequest{PartnerUniqueID: "order-1"}, o)
+		require.NoError(t, err)
+		assert.Equal(t, PaymentStatusAuthorized, res.Status)
+		assert.Equal(t, 3, *polls)
+
+		require.Len(t, transitions, 2)
+		assert.Equal(t, PaymentStatus(""), transitions[0].From)
+		assert.Equal(t, PaymentStatusPending, transitions[0].To)
+		assert.Equal(t, PaymentStatusPending, transitions[1].From)
+		assert.Equal(t, PaymentStatusAuthorized, transitions[1].To)
+		assert.Len(t, ch, 2)
+	})
+
+	t.Run("should time out with the last status", func(t *testing.T) {
+		client, _ := statusClient(PaymentStatusPending)
+		api := newTestPaymentClient(t, client, Config{})
+
+		o := opts
+		o.Timeout = 20 * time.Millisecond
+		_, err := WaitForFinalStatus(ctx, api, StatusRequest{}, o)
+		assert.ErrorIs(t, err, ErrWaitTimeout)
+
+		var te *WaitTimeoutError
+		require.True(t, errors.As(err, &te))
+		assert.Equal(t, PaymentStatusPending, te.Last.Status)
+	})
+
+	t.Run("should honor context cancellation", func(t *testing.T) {
+		client, _ := statusClient(PaymentStatusPending)
+		api := newTestPaymentClient(t, client, Config{})
+
+		cctx, cancel := context.WithCancel(ctx)
+		o := opts
+		o.OnTransition = func(StatusTransition) { cancel() }
+		_, err := WaitForFinalStatus(cctx, api, StatusRequest{}, o)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should stop when a custom condition holds", func(t *testing.T) {
+		client, polls := statusClient(PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCaptured)
+		api := newTestPaymentClient(t, client, Config{})
+
+		o := opts
+		o.Done = func(s PaymentStatus) bool { return s == PaymentStatusCaptured }
+		res, err := NewTunaService(nil, api).WaitForFinalStatus(ctx, StatusRequest{}, o)
+		require.NoError(t, err)
+		assert.Equal(t, PaymentStatusCaptured, res.Status)
+		assert.Equal(t, 3, *polls)
+	})
+}